@@ -5,9 +5,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	jif "github.com/Gaurav-Gosain/jif/core"
+	"github.com/Gaurav-Gosain/jif/internal/config"
 	"github.com/charmbracelet/fang"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
@@ -20,13 +26,20 @@ var (
 )
 
 func main() {
+	var (
+		opts  jif.Options
+		flags = config.Default()
+	)
+
 	rootCmd := &cobra.Command{
-		Use:   "jif [gif-file-or-url]",
+		Use:   "jif [gif-file-or-url]...",
 		Short: "A modern GIF viewer for your terminal",
 		Long: `jif - A modern, high-performance GIF viewer for your terminal
 
 Displays GIF animations in your terminal using halfblock rendering for
-2x vertical resolution. Supports local files and remote URLs.
+2x vertical resolution. Supports local files and remote URLs, and plays
+several files, globs or directories as a playlist. Started with a single
+directory, or no arguments, it opens a file browser instead.
 
 Features:
   - Halfblock rendering (2x resolution)
@@ -40,14 +53,387 @@ Features:
   # View a remote GIF
   jif https://example.com/animation.gif
 
+  # Pick a GIF from the current directory
+  jif
+
+  # Play every GIF in several places with [ and ]
+  jif ./animations/ 'more/*.gif'
+
+  # Watch a directory of GIFs play side by side
+  jif --grid ./animations/
+
+  # Play twice in the scrollback, 12 rows tall, then exit
+  jif --inline --height 12 --loop 2 animation.gif
+
+  # Print the first frame as ANSI text
+  jif cat animation.gif
+
+  # Play once to stdout, e.g. in CI logs
+  jif stream --loop 1 animation.gif
+
+  # Crop to fill the whole terminal
+  jif --fit cover animation.gif
+
   # Press ? while viewing for keybindings`,
 		Version:      version,
 		SilenceUsage: true,
-		Args:         cobra.ExactArgs(1),
+		Args:         cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig(cmd, flags)
+			if err != nil {
+				return err
+			}
+			applyConfig(cfg, &opts)
+
+			// Inline playback ends on its own, as the GIF's loop count says
+			// or after one play of a GIF that loops forever
+			if opts.Inline && !cmd.Flags().Changed("loop") {
+				opts.Loop = jif.LoopFinite
+			}
+
+			// Print a frame instead of playing when output is redirected
+			if len(args) > 0 && !opts.Inline && !term.IsTerminal(os.Stdout.Fd()) {
+				return jif.Cat(cmd.OutOrStdout(), args, opts)
+			}
+			return jif.Run(args, opts)
+		},
+	}
+
+	// Settings that the config file and JIF_* variables can also set
+	persistent := rootCmd.PersistentFlags()
+	persistent.StringVar(&flags.Fit, "fit", flags.Fit, "how to fit the image: contain, cover, stretch or original")
+	persistent.Float64Var(&flags.CellAspect, "cell-aspect", flags.CellAspect, "terminal cell height/width ratio (0 detects it)")
+	persistent.Float64Var(&flags.Speed, "speed", flags.Speed, "playback speed multiplier")
+	persistent.StringVar((*string)(&flags.Loop), "loop", string(flags.Loop), "forever, gif to honour the file's loop count, or a number of plays")
+	persistent.StringVar(&flags.Color, "color", flags.Color, "colour mode: auto, truecolor, 256 or 16")
+	persistent.StringVar(&flags.Background, "background", flags.Background, "backdrop for transparent pixels: terminal, checkerboard or #rrggbb")
+	persistent.StringVar(&flags.Keymap, "keymap", flags.Keymap, "keybinding preset: default, vim or media")
+	persistent.StringVar(&flags.Theme.Name, "theme", flags.Theme.Name, "UI theme: auto, dark, light, high-contrast or high-contrast-light")
+
+	rootCmd.Flags().BoolVar(&opts.Grid, "grid", false, "start in the grid gallery when viewing several files")
+	rootCmd.Flags().BoolVar(&opts.Inline, "inline", false, "play in the scrollback instead of full screen, leaving a frame printed (loops as the GIF says, once if forever, unless --loop is given)")
+	rootCmd.Flags().IntVar(&opts.Height, "height", 0, "rows to play in with --inline (default 20), or to print when output is redirected")
+	rootCmd.Flags().IntVar(&opts.ExitFrame, "exit-frame", 0, "frame to leave printed with --inline (0 keeps the one on screen)")
+
+	var catOpts jif.Options
+	catCmd := &cobra.Command{
+		Use:   "cat <gif-file-or-url>...",
+		Short: "Print a single frame as ANSI text",
+		Long: `Print one frame of each GIF to stdout as halfblock ANSI text, without
+starting the interactive viewer. This is also what jif does when stdout
+is not a terminal.
+
+The first frame is printed unless --frame or --time picks another. The
+output is as wide as the terminal, or 80 columns when redirected, and as
+tall as the image needs unless --height is given. Colours are reduced to
+what the terminal supports; redirected output is truecolor unless --color
+is given.`,
+		Example: `  # Print the first frame
+  jif cat animation.gif
+
+  # Print the fifth frame, 40 columns wide
+  jif cat --frame 5 --width 40 animation.gif
+
+  # Print whatever is on screen 1.2 seconds in
+  jif cat --time 1.2s animation.gif > frame.ans`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig(cmd, flags)
+			if err != nil {
+				return err
+			}
+			applyConfig(cfg, &catOpts)
+			return jif.Cat(cmd.OutOrStdout(), args, catOpts)
+		},
+	}
+	catCmd.Flags().IntVar(&catOpts.Frame, "frame", 0, "1-based frame to print (default the first)")
+	catCmd.Flags().DurationVar(&catOpts.Time, "time", 0, "print the frame on screen this far into playback, e.g. 1.2s")
+	catCmd.Flags().IntVar(&catOpts.Width, "width", 0, "output width in columns (default the terminal width)")
+	catCmd.Flags().IntVar(&catOpts.Height, "height", 0, "output height in rows (default what the image needs)")
+	rootCmd.AddCommand(catCmd)
+
+	var streamOpts jif.Options
+	streamCmd := &cobra.Command{
+		Use:   "stream <gif-file-or-url>...",
+		Short: "Play to stdout without the interactive viewer",
+		Long: `Play each GIF to stdout, redrawing in place with cursor movement, for
+CI logs and watch-style use. Frame delays and the GIF's own loop count are
+honoured unless --loop is given, playing a GIF that loops forever once, and
+the last frame stays on screen. Ctrl+C
+stops playback and restores the cursor.`,
+		Example: `  # Play as the GIF's loop count says
+  jif stream animation.gif
+
+  # Play once, 60 columns wide, at double speed
+  jif stream --loop 1 --width 60 --speed 2 animation.gif`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig(cmd, flags)
+			if err != nil {
+				return err
+			}
+			applyConfig(cfg, &streamOpts)
+			if !cmd.Flags().Changed("loop") {
+				streamOpts.Loop = jif.LoopFinite
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return jif.Stream(ctx, cmd.OutOrStdout(), args, streamOpts)
+		},
+	}
+	streamCmd.Flags().IntVar(&streamOpts.Width, "width", 0, "output width in columns (default the terminal width)")
+	streamCmd.Flags().IntVar(&streamOpts.Height, "height", 0, "output height in rows (default what the image needs)")
+	rootCmd.AddCommand(streamCmd)
+
+	var (
+		exportOpts   jif.Options
+		exportFormat string
+	)
+	exportCmd := &cobra.Command{
+		Use:   "export <gif-file-or-url> <output>",
+		Short: "Export the rendered frames to a file",
+		Long: `Render every frame of a GIF as halfblock ANSI text and write it to a file,
+keeping the frame delays. The output is "-" for stdout.
+
+Formats:
+  asciicast  asciinema v2 recording, for asciinema-player (.cast)
+  html       self-contained page of coloured text, animated with CSS
+  svg        animated SVG drawing each cell as two coloured rectangles
+
+The format is taken from the output's extension unless --format is given.
+The size does not depend on the terminal: the output is 80 columns wide
+unless --width is given, so the same options always give the same file.`,
+		Example: `  # Record a preview for asciinema-player
+  jif export --format asciicast animation.gif out.cast
+
+  # 40 columns wide at half speed
+  jif export --width 40 --speed 0.5 animation.gif out.cast
+
+  # Share the rendering in docs or code review
+  jif export animation.gif preview.html
+  jif export --loop 1 animation.gif preview.svg`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfig(cmd, flags)
+			if err != nil {
+				return err
+			}
+			applyConfig(cfg, &exportOpts)
+
+			source, output := args[0], args[1]
+			format := exportFormat
+			if format == "" {
+				format = strings.TrimPrefix(filepath.Ext(output), ".")
+			}
+
+			if output == "-" {
+				return jif.Export(cmd.OutOrStdout(), source, format, exportOpts)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			if err := jif.Export(f, source, format, exportOpts); err != nil {
+				f.Close()
+				os.Remove(output)
+				return err
+			}
+			return f.Close()
+		},
+	}
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "output format: asciicast, html or svg (default from the output extension)")
+	exportCmd.Flags().IntVar(&exportOpts.Width, "width", 0, "output width in columns (default 80)")
+	exportCmd.Flags().IntVar(&exportOpts.Height, "height", 0, "output height in rows (default what the image needs)")
+	rootCmd.AddCommand(exportCmd)
+
+	var (
+		extractOpts jif.ExtractOptions
+		extractDir  string
+		composited  bool
+	)
+	extractCmd := &cobra.Command{
+		Use:   "extract <gif-file-or-url>",
+		Short: "Write frames to PNG files",
+		Long: `Write the frames of a GIF to PNG files named frame-001.png and so on,
+with a frames.json sidecar giving each frame's delay, disposal method and
+offset. delay_ms is the delay stored in the file, which may be 0;
+playback_delay_ms is how long jif shows the frame.
+
+Frames are composited onto the full canvas with the same disposal handling
+as playback, or with --raw written as the frame's own rectangle exactly as
+stored in the file. --range picks 1-based frames, e.g. 10:20, 10: or 5.`,
+		Example: `  # Every frame, composited
+  jif extract animation.gif --out frames/
+
+  # Frames 10 to 20 as stored in the file
+  jif extract animation.gif --out frames/ --range 10:20 --raw`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// --composited=false asks for the raw rectangles too
+			extractOpts.Raw = extractOpts.Raw || !composited
+			n, err := jif.Extract(args[0], extractDir, extractOpts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %d frames to %s\n", n, extractDir)
+			return nil
+		},
+	}
+	extractCmd.Flags().StringVarP(&extractDir, "out", "o", "", "directory to write the frames to")
+	extractCmd.Flags().StringVar(&extractOpts.Range, "range", "", "1-based frames to write, e.g. 10:20 (default all)")
+	extractCmd.Flags().BoolVar(&composited, "composited", true, "write frames composited onto the full canvas (default)")
+	extractCmd.Flags().BoolVar(&extractOpts.Raw, "raw", false, "write each frame's own rectangle as stored in the file")
+	extractCmd.MarkFlagsMutuallyExclusive("composited", "raw")
+	extractCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(extractCmd)
+
+	var (
+		sheetOpts     jif.SheetOptions
+		contactOpts   jif.Options
+		sheetOut      string
+		sheetTerminal bool
+	)
+	sheetCmd := &cobra.Command{
+		Use:   "sheet <gif-file-or-url>",
+		Short: "Lay frames out on a sprite sheet or contact sheet",
+		Long: `Lay all frames of a GIF, or every Nth with --every, out in rows.
+
+With --out the composited frames are drawn on a single PNG sprite sheet,
+with a JSON atlas next to it (sheet.png gets sheet.json) giving each
+frame's rectangle and duration. Durations include the skipped frames, so
+the sampled animation keeps its timing. The default layout is close to
+square.
+
+With --terminal a contact sheet of labelled halfblock tiles is printed
+instead, as wide as the terminal or --width.`,
+		Example: `  # Sprite sheet, 8 frames per row
+  jif sheet animation.gif --out sheet.png --columns 8
+
+  # Quick preview of every 5th frame in the terminal
+  jif sheet animation.gif --terminal --every 5`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sheetTerminal {
+				cfg, _, err := loadConfig(cmd, flags)
+				if err != nil {
+					return err
+				}
+				applyConfig(cfg, &contactOpts)
+				return jif.ContactSheet(cmd.OutOrStdout(), args[0], sheetOpts, contactOpts)
+			}
+
+			n, err := jif.SpriteSheet(args[0], sheetOut, sheetOpts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %d frames to %s\n", n, sheetOut)
+			return nil
+		},
+	}
+	sheetCmd.Flags().StringVarP(&sheetOut, "out", "o", "", "PNG file to write the sprite sheet to")
+	sheetCmd.Flags().BoolVar(&sheetTerminal, "terminal", false, "print a contact sheet to the terminal instead")
+	sheetCmd.Flags().IntVar(&sheetOpts.Columns, "columns", 0, "frames per row (default a near-square layout, or what fits the terminal)")
+	sheetCmd.Flags().IntVar(&sheetOpts.Every, "every", 1, "keep every Nth frame")
+	sheetCmd.Flags().IntVar(&contactOpts.Width, "width", 0, "contact sheet width in columns (default the terminal width)")
+	sheetCmd.MarkFlagsMutuallyExclusive("out", "terminal")
+	sheetCmd.MarkFlagsOneRequired("out", "terminal")
+	rootCmd.AddCommand(sheetCmd)
+
+	var editOpts jif.EditOptions
+	editCmd := &cobra.Command{
+		Use:   "edit <gif-file-or-url> <output.gif>",
+		Short: "Trim, crop, resize or retime a GIF",
+		Long: `Write a new GIF with edits applied, in this order: keep a frame range,
+crop to a rectangle, scale, reverse, set every frame's delay, change the
+speed and set the loop count. Frames are composited and re-encoded, each
+with its own palette.
+
+The result is optimised unless --optimize=false is given: repeated frames
+are merged, each frame stores only the area that changed with unchanged
+pixels left transparent, and each frame's disposal is chosen to keep the
+next one small. --colors shares one smaller palette between all frames.
+The sizes before and after are printed.
+
+--speed and --loop change the written file only when given here; the
+configured playback settings are not applied, and the other viewer flags
+such as --fit are rejected. The same edits are available from the viewer
+with the save-as key (S).`,
+		Example: `  # Keep frames 10 to 40, cropped and scaled to 320 wide
+  jif edit animation.gif clip.gif --range 10:40 --crop 400x300+20+10 --scale 320x
+
+  # Half size with a sharp filter, twice as fast, playing once
+  jif edit animation.gif small.gif --scale 50% --filter nearest --speed 2 --loop 1
+
+  # Even 50ms delays, backwards
+  jif edit animation.gif rewind.gif --delay 50ms --reverse
+
+  # Shrink a GIF without other edits, down to 64 colours
+  jif edit animation.gif small.gif --colors 64`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return jif.Run(args[0])
+			// The other viewer settings have nothing to apply to in a file
+			for _, name := range []string{"fit", "cell-aspect", "color", "background", "keymap", "theme"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("jif edit does not take the viewer setting --%s", name)
+				}
+			}
+			if cmd.Flags().Changed("speed") {
+				editOpts.Speed = flags.Speed
+			}
+			if cmd.Flags().Changed("loop") {
+				editOpts.Loop = string(flags.Loop)
+			}
+			stats, err := jif.Edit(args[0], args[1], editOpts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote %s: %s\n", args[1], stats)
+			return nil
 		},
 	}
+	editCmd.Flags().StringVar(&editOpts.Range, "range", "", "1-based frames to keep, e.g. 10:20 (default all)")
+	editCmd.Flags().StringVar(&editOpts.Crop, "crop", "", "canvas rectangle to keep, as WxH+X+Y")
+	editCmd.Flags().StringVar(&editOpts.Scale, "scale", "", "new size as WxH, 320x, x240 or a percentage such as 50%")
+	editCmd.Flags().StringVar(&editOpts.Filter, "filter", "lanczos3", "resize filter: nearest, bilinear, bicubic, mitchell, lanczos2 or lanczos3")
+	editCmd.Flags().BoolVar(&editOpts.Reverse, "reverse", false, "play the frames backwards")
+	editCmd.Flags().DurationVar(&editOpts.Delay, "delay", 0, "give every frame this delay, e.g. 50ms")
+	editCmd.Flags().BoolVar(&editOpts.Optimize, "optimize", true, "store only changed pixels and merge repeated frames")
+	editCmd.Flags().IntVar(&editOpts.Colors, "colors", 0, "reduce to one palette of at most this many colours, 2 to 256 (default keep all)")
+	rootCmd.AddCommand(editCmd)
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "config",
+		Short: "Print the effective configuration",
+		Long: `Print the configuration jif would use, as TOML.
+
+Settings come from the built-in defaults, then the config file
+($XDG_CONFIG_HOME/jif/config.toml, config.yaml or config.yml, or the file
+named by $JIF_CONFIG), then JIF_* environment variables such as JIF_FIT
+and JIF_SPEED, and finally command-line flags.`,
+		Example: `  # Show the merged configuration
+  jif config
+
+  # Start a config file from the current settings
+  jif config > ~/.config/jif/config.toml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, path, err := loadConfig(cmd, flags)
+			if err != nil {
+				return err
+			}
+			out, err := cfg.Encode()
+			if err != nil {
+				return err
+			}
+
+			if path == "" {
+				path = "none"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "# config file: %s\n%s", path, out)
+			return nil
+		},
+	})
 
 	// Execute with fang
 	if err := fang.Execute(
@@ -58,3 +444,53 @@ Features:
 		os.Exit(1)
 	}
 }
+
+// loadConfig merges the config file and environment with the flags given on
+// the command line, returning the path of the config file that was read
+func loadConfig(cmd *cobra.Command, flags config.Config) (config.Config, string, error) {
+	cfg, path, err := config.Load(os.Getenv)
+	if err != nil {
+		return cfg, path, err
+	}
+
+	changed := cmd.Flags().Changed
+	if changed("fit") {
+		cfg.Fit = flags.Fit
+	}
+	if changed("cell-aspect") {
+		cfg.CellAspect = flags.CellAspect
+	}
+	if changed("speed") {
+		cfg.Speed = flags.Speed
+	}
+	if changed("loop") {
+		cfg.Loop = flags.Loop
+	}
+	if changed("color") {
+		cfg.Color = flags.Color
+	}
+	if changed("background") {
+		cfg.Background = flags.Background
+	}
+	if changed("keymap") {
+		cfg.Keymap = flags.Keymap
+	}
+	if changed("theme") {
+		cfg.Theme.Name = flags.Theme.Name
+	}
+	return cfg, path, nil
+}
+
+// applyConfig copies the merged configuration into the viewer options
+func applyConfig(cfg config.Config, opts *jif.Options) {
+	opts.Fit = cfg.Fit
+	opts.CellAspect = cfg.CellAspect
+	opts.Speed = cfg.Speed
+	opts.Loop = string(cfg.Loop)
+	opts.Color = cfg.Color
+	opts.Background = cfg.Background
+	opts.Keymap = cfg.Keymap
+	opts.Keys = cfg.Keys
+	opts.Theme = cfg.Theme.Name
+	opts.ThemeColors = cfg.Theme.Colors()
+}
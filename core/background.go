@@ -0,0 +1,146 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// backgroundMode is what transparent pixels are drawn against
+type backgroundMode int
+
+const (
+	backgroundTerminal backgroundMode = iota
+	backgroundChecker
+	backgroundSolid
+)
+
+var backgroundNames = []string{"terminal", "checkerboard", "solid"}
+
+func (b backgroundMode) String() string {
+	return backgroundNames[b]
+}
+
+// background is the backdrop for transparent pixels
+type background struct {
+	Mode backgroundMode
+
+	// Color is used by the solid mode
+	Color color.RGBA
+}
+
+// checkerSize is the side of a checkerboard square in rendered pixels
+const checkerSize = 4
+
+// checkerColors are the light and dark checkerboard squares
+var checkerColors = [2]color.RGBA{
+	{0x99, 0x99, 0x99, 0xff},
+	{0x66, 0x66, 0x66, 0xff},
+}
+
+// defaultSolid is the solid background when none was given
+var defaultSolid = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+// parseBackground parses terminal, checkerboard or a #rrggbb colour. Empty
+// means terminal.
+func parseBackground(s string) (background, error) {
+	switch strings.ToLower(s) {
+	case "", "terminal":
+		return background{Mode: backgroundTerminal, Color: defaultSolid}, nil
+	case "checkerboard", "checker":
+		return background{Mode: backgroundChecker, Color: defaultSolid}, nil
+	}
+
+	if strings.HasPrefix(s, "#") {
+		if _, invalid := lipgloss.Color(s).(lipgloss.NoColor); !invalid {
+			c := color.RGBAModel.Convert(lipgloss.Color(s)).(color.RGBA)
+			return background{Mode: backgroundSolid, Color: c}, nil
+		}
+	}
+	return background{}, fmt.Errorf("invalid background %q (want terminal, checkerboard or #rrggbb)", s)
+}
+
+// next returns the background that follows b, keeping its solid colour
+func (b background) next() background {
+	b.Mode = (b.Mode + 1) % backgroundMode(len(backgroundNames))
+	return b
+}
+
+// label describes a non-default background for the status bar
+func (b background) label() string {
+	switch b.Mode {
+	case backgroundChecker:
+		return "bg checker"
+	case backgroundSolid:
+		return "bg " + hexColor(b.Color)
+	}
+	return ""
+}
+
+// ============================================================================
+// Alpha Blending
+// ============================================================================
+
+// flatten blends img over the background so that no pixel is partly
+// transparent. With the terminal background fully transparent pixels are
+// kept, letting the terminal show through, and partly transparent ones are
+// blended against terminal, the terminal's colour.
+func (b background) flatten(img image.Image, terminal color.Color) image.Image {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+
+	switch b.Mode {
+	case backgroundChecker:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				square := ((x-bounds.Min.X)/checkerSize + (y-bounds.Min.Y)/checkerSize) % 2
+				out.SetRGBA(x, y, checkerColors[square])
+			}
+		}
+		draw.Draw(out, bounds, img, bounds.Min, draw.Over)
+
+	case backgroundSolid:
+		draw.Draw(out, bounds, image.NewUniform(b.Color), image.Point{}, draw.Src)
+		draw.Draw(out, bounds, img, bounds.Min, draw.Over)
+
+	default:
+		if terminal == nil {
+			terminal = color.Black
+		}
+		tr, tg, tb, _ := terminal.RGBA()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, bl, a := img.At(x, y).RGBA()
+				if a == 0 || a == 0xffff {
+					out.Set(x, y, img.At(x, y))
+					continue
+				}
+				// Colours are premultiplied, so only the backdrop is scaled
+				rest := 0xffff - a
+				out.SetRGBA(x, y, color.RGBA{
+					R: uint8((r + tr*rest/0xffff) >> 8),
+					G: uint8((g + tg*rest/0xffff) >> 8),
+					B: uint8((bl + tb*rest/0xffff) >> 8),
+					A: 0xff,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// ============================================================================
+// Background Cycling
+// ============================================================================
+
+// cycleBackground switches to the next background and renders the frames
+// again against it
+func (m *model) cycleBackground() tea.Cmd {
+	m.Background = m.Background.next()
+	return m.reprocess()
+}
@@ -0,0 +1,107 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseBackground(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    background
+		wantErr bool
+	}{
+		{"", background{Mode: backgroundTerminal, Color: defaultSolid}, false},
+		{"Checkerboard", background{Mode: backgroundChecker, Color: defaultSolid}, false},
+		{"#102030", background{Mode: backgroundSolid, Color: color.RGBA{0x10, 0x20, 0x30, 0xff}}, false},
+		{"#fff", background{Mode: backgroundSolid, Color: color.RGBA{0xff, 0xff, 0xff, 0xff}}, false},
+		{"#zzzzzz", background{}, true},
+		{"blue", background{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseBackground(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseBackground(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseBackground(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	// A clear pixel, a half transparent red one and an opaque blue one
+	img := image.NewRGBA(image.Rect(0, 0, 8, 1))
+	img.SetRGBA(1, 0, color.RGBA{0x80, 0, 0, 0x80})
+	img.SetRGBA(2, 0, color.RGBA{0, 0, 0xff, 0xff})
+
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	tests := []struct {
+		name     string
+		bg       background
+		terminal color.Color
+		want     []color.RGBA
+	}{
+		{
+			name:     "terminal keeps clear pixels and blends partial ones",
+			bg:       background{Mode: backgroundTerminal},
+			terminal: white,
+			want:     []color.RGBA{{}, {0xff, 0x7f, 0x7f, 0xff}, {0, 0, 0xff, 0xff}},
+		},
+		{
+			name: "terminal defaults to black",
+			bg:   background{Mode: backgroundTerminal},
+			want: []color.RGBA{{}, {0x80, 0, 0, 0xff}, {0, 0, 0xff, 0xff}},
+		},
+		{
+			name: "solid",
+			bg:   background{Mode: backgroundSolid, Color: white},
+			want: []color.RGBA{white, {0xff, 0x7f, 0x7f, 0xff}, {0, 0, 0xff, 0xff}},
+		},
+		{
+			name: "checkerboard",
+			bg:   background{Mode: backgroundChecker},
+			want: []color.RGBA{checkerColors[0], {0xcc, 0x4c, 0x4c, 0xff}, {0, 0, 0xff, 0xff}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.bg.flatten(img, tt.terminal)
+			for x, want := range tt.want {
+				got := color.RGBAModel.Convert(out.At(x, 0)).(color.RGBA)
+				if got != want {
+					t.Errorf("pixel %d = %v, want %v", x, got, want)
+				}
+			}
+		})
+	}
+
+	// Squares alternate every checkerSize pixels
+	out := background{Mode: backgroundChecker}.flatten(img, nil)
+	if got := out.At(checkerSize, 0); got != checkerColors[1] {
+		t.Errorf("pixel %d = %v, want the second checker colour", checkerSize, got)
+	}
+}
+
+func TestCycleBackground(t *testing.T) {
+	m := newZoomModel()
+	m.Ready = true
+
+	want := []backgroundMode{backgroundChecker, backgroundSolid, backgroundTerminal}
+	for _, mode := range want {
+		_, cmd := m.Update(tea.KeyPressMsg{Code: 'b', Text: "b"})
+		if m.Background.Mode != mode {
+			t.Fatalf("Background = %v, want %v", m.Background.Mode, mode)
+		}
+		if cmd == nil || m.Ready {
+			t.Errorf("cycling to %v did not render the frames again", mode)
+		}
+		m.Ready = true
+	}
+}
@@ -0,0 +1,582 @@
+package jif
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// browserSort orders the files listed in the browser
+type browserSort int
+
+const (
+	sortByName browserSort = iota
+	sortBySize
+	sortByDate
+)
+
+var browserSortNames = []string{"name", "size", "date"}
+
+func (s browserSort) String() string {
+	return browserSortNames[s]
+}
+
+// next returns the sort order that follows s, wrapping around
+func (s browserSort) next() browserSort {
+	return (s + 1) % browserSort(len(browserSortNames))
+}
+
+// browserEntry is one GIF listed in the browser
+type browserEntry struct {
+	Name    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// browser holds the file picker state
+type browser struct {
+	Dir       string
+	Entries   []browserEntry
+	Sort      browserSort
+	Filter    string
+	Filtering bool
+	Selected  int
+	Offset    int
+
+	// Previews are rendered at the size of the preview pane, keyed by path
+	Previews   map[string]*player
+	previewGen int
+}
+
+// previewLoadedMsg carries a browser preview rendered in the background
+type previewLoadedMsg struct {
+	source string
+	frames []string
+	loaded loadedGIF
+	err    error
+}
+
+// previewTickMsg advances the highlighted preview; gen discards ticks for a
+// file that is no longer highlighted
+type previewTickMsg struct {
+	gen int
+}
+
+// ============================================================================
+// Directory Listing
+// ============================================================================
+
+// readBrowserDir lists the GIF files directly inside dir
+func readBrowserDir(dir string) ([]browserEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var files []browserEntry
+	for _, entry := range entries {
+		if entry.IsDir() || !isGIFFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, browserEntry{
+			Name:    entry.Name(),
+			Path:    filepath.Join(dir, entry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// newBrowser lists dir sorted by name
+func newBrowser(dir string) (*browser, error) {
+	entries, err := readBrowserDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &browser{Dir: dir, Entries: entries, Previews: make(map[string]*player)}
+	b.sortEntries()
+	return b, nil
+}
+
+// sortEntries orders the entries by the current sort; sizes and dates list
+// the largest and newest first
+func (b *browser) sortEntries() {
+	slices.SortStableFunc(b.Entries, func(x, y browserEntry) int {
+		switch b.Sort {
+		case sortBySize:
+			if c := cmp.Compare(y.Size, x.Size); c != 0 {
+				return c
+			}
+		case sortByDate:
+			if c := y.ModTime.Compare(x.ModTime); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(strings.ToLower(x.Name), strings.ToLower(y.Name))
+	})
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in order,
+// ignoring case
+func fuzzyMatch(pattern, s string) bool {
+	rest := []rune(strings.ToLower(s))
+	for _, r := range strings.ToLower(pattern) {
+		i := slices.Index(rest, r)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return true
+}
+
+// visible returns the entries matching the filter, in sort order
+func (b *browser) visible() []browserEntry {
+	if b.Filter == "" {
+		return b.Entries
+	}
+
+	var matches []browserEntry
+	for _, entry := range b.Entries {
+		if fuzzyMatch(b.Filter, entry.Name) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// selectedEntry returns the highlighted entry, if any
+func (b *browser) selectedEntry() (browserEntry, bool) {
+	entries := b.visible()
+	if b.Selected < 0 || b.Selected >= len(entries) {
+		return browserEntry{}, false
+	}
+	return entries[b.Selected], true
+}
+
+// ============================================================================
+// Browser
+// ============================================================================
+
+// browserListWidth returns the width of the file list, the preview pane
+// taking the rest of the terminal
+func (m model) browserListWidth() int {
+	return min(max(30, m.Width*2/5), 60, m.Width)
+}
+
+// browserListRows returns how many entries fit between the header and footer
+func (m model) browserListRows() int {
+	return max(1, m.Height-2)
+}
+
+// browserPreviewSize returns the cells available to the preview inside its
+// border, or zero when the terminal is too narrow for a preview
+func (m model) browserPreviewSize() (width, height int) {
+	width = m.Width - m.browserListWidth() - 3
+	height = m.Height - 4
+	if width < 8 || height < 4 {
+		return 0, 0
+	}
+	return width, height
+}
+
+// scrollBrowser keeps the highlighted entry on screen
+func (m *model) scrollBrowser() {
+	b := m.Browser
+	rows := m.browserListRows()
+	if b.Selected < b.Offset {
+		b.Offset = b.Selected
+	}
+	if b.Selected >= b.Offset+rows {
+		b.Offset = b.Selected - rows + 1
+	}
+}
+
+// moveBrowserSelection moves the highlight by delta entries and loads the
+// new preview
+func (m *model) moveBrowserSelection(delta int) tea.Cmd {
+	b := m.Browser
+	selected := max(0, min(b.Selected+delta, len(b.visible())-1))
+	if selected == b.Selected {
+		return nil
+	}
+
+	b.Selected = selected
+	m.scrollBrowser()
+	return m.loadPreview()
+}
+
+// resetBrowserSelection moves the highlight to the top after the list changed
+func (m *model) resetBrowserSelection() tea.Cmd {
+	m.Browser.Selected = 0
+	m.Browser.Offset = 0
+	return m.loadPreview()
+}
+
+// loadPreview animates the highlighted file, rendering it first if needed
+func (m *model) loadPreview() tea.Cmd {
+	b := m.Browser
+	b.previewGen++
+
+	m.prunePreviews()
+
+	entry, ok := b.selectedEntry()
+	width, height := m.browserPreviewSize()
+	if !ok || !m.ShowBrowser || width == 0 {
+		return nil
+	}
+
+	if preview, ok := b.Previews[entry.Path]; ok {
+		if len(preview.Frames) > 0 {
+			return m.previewTick()
+		}
+		return nil
+	}
+
+	b.Previews[entry.Path] = &player{Source: entry.Path, Loading: true}
+	tile := m.tileModel(width, height)
+
+	return func() tea.Msg {
+		frames, loaded, err := loadPlayerFrames(tile, entry.Path, nil)
+		return previewLoadedMsg{source: entry.Path, frames: frames, loaded: loaded, err: err}
+	}
+}
+
+// prunePreviews drops the previews of files scrolled out of the list or
+// hidden by the filter, so browsing a large directory keeps only the frames
+// of the rows on screen
+func (m *model) prunePreviews() {
+	b := m.Browser
+	entries := b.visible()
+	end := min(len(entries), b.Offset+m.browserListRows())
+
+	listed := make(map[string]bool, end-b.Offset)
+	for _, entry := range entries[min(b.Offset, end):end] {
+		listed[entry.Path] = true
+	}
+	for path := range b.Previews {
+		if !listed[path] {
+			delete(b.Previews, path)
+		}
+	}
+}
+
+// previewTick schedules the next frame of the highlighted preview
+func (m model) previewTick() tea.Cmd {
+	entry, _ := m.Browser.selectedEntry()
+	preview := m.Browser.Previews[entry.Path]
+	gen := m.Browser.previewGen
+
+	return tea.Tick(preview.delay(), func(time.Time) tea.Msg {
+		return previewTickMsg{gen: gen}
+	})
+}
+
+func (m *model) handlePreviewLoaded(msg previewLoadedMsg) (tea.Model, tea.Cmd) {
+	preview, ok := m.Browser.Previews[msg.source]
+	if !ok {
+		return m, nil
+	}
+
+	preview.Loading = false
+	preview.Err = msg.err
+	preview.GIF = msg.loaded.gif
+	preview.Stats = msg.loaded.stats
+	preview.Frames = msg.frames
+
+	if entry, ok := m.Browser.selectedEntry(); !ok || entry.Path != msg.source || msg.err != nil {
+		return m, nil
+	}
+	return m, m.previewTick()
+}
+
+func (m *model) handlePreviewTick(msg previewTickMsg) (tea.Model, tea.Cmd) {
+	if !m.ShowBrowser || msg.gen != m.Browser.previewGen {
+		return m, nil
+	}
+
+	entry, ok := m.Browser.selectedEntry()
+	if !ok {
+		return m, nil
+	}
+	m.Browser.Previews[entry.Path].advance()
+	return m, m.previewTick()
+}
+
+// resizeBrowser drops previews rendered for the old terminal size
+func (m *model) resizeBrowser() tea.Cmd {
+	m.Browser.Previews = make(map[string]*player)
+	m.scrollBrowser()
+	return m.loadPreview()
+}
+
+// openBrowserSelection shows the highlighted file in the viewer, with the
+// listed files as the playlist
+func (m *model) openBrowserSelection() tea.Cmd {
+	b := m.Browser
+	entries := b.visible()
+	if b.Selected >= len(entries) || m.Loading {
+		return nil
+	}
+
+	playlist := make([]string, len(entries))
+	for i, entry := range entries {
+		playlist[i] = entry.Path
+	}
+
+	// Reopening the file that is already loaded resumes it
+	current := m.GIF != nil && m.pendingSource == "" && m.currentSource() == playlist[b.Selected]
+	if !slices.Equal(playlist, m.Playlist) {
+		m.Playlist = playlist
+		m.Grid = nil
+	}
+	m.ShowBrowser = false
+	b.previewGen++
+
+	if current {
+		m.PlaylistIndex = b.Selected
+		return nil
+	}
+
+	// The preview already decoded the file
+	if preview, ok := b.Previews[playlist[b.Selected]]; ok && preview.GIF != nil {
+		if m.preloaded == nil {
+			m.preloaded = make(map[string]loadedGIF)
+		}
+		m.preloaded[preview.Source] = loadedGIF{gif: preview.GIF, stats: preview.Stats}
+	}
+	return m.showFile(b.Selected)
+}
+
+// returnToBrowser leaves the viewer for the file picker
+func (m *model) returnToBrowser() tea.Cmd {
+	m.ShowBrowser = true
+	m.ShowGrid = false
+	m.gridGen++
+	return m.loadPreview()
+}
+
+// quitOrBrowse returns to the browser when jif was started from one and
+// quits otherwise
+func (m *model) quitOrBrowse() tea.Cmd {
+	if m.Browser != nil {
+		return m.returnToBrowser()
+	}
+	return m.quit()
+}
+
+func (m *model) handleBrowserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := m.Browser
+	keys := m.keyMap()
+
+	// The filter is a text field: printable keys type, and besides the
+	// arrows only Back and Open keep their bindings
+	if b.Filtering {
+		text := msg.Key().Text
+		switch {
+		case text != "" && unicode.IsPrint([]rune(text)[0]):
+			b.Filter += text
+			return m, m.resetBrowserSelection()
+		case msg.String() == "backspace" && b.Filter != "":
+			runes := []rune(b.Filter)
+			b.Filter = string(runes[:len(runes)-1])
+			return m, m.resetBrowserSelection()
+		case key.Matches(msg, keys.Back):
+			b.Filtering = false
+			b.Filter = ""
+			return m, m.resetBrowserSelection()
+		case key.Matches(msg, keys.Open):
+			b.Filtering = false
+			return m, nil
+		case slices.Contains([]string{"up", "down", "ctrl+c"}, msg.String()):
+			// Handled below
+		default:
+			return m, nil
+		}
+	}
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		return m, m.moveBrowserSelection(-1)
+	case key.Matches(msg, keys.Down):
+		return m, m.moveBrowserSelection(1)
+	case key.Matches(msg, keys.PageUp):
+		return m, m.moveBrowserSelection(-m.browserListRows())
+	case key.Matches(msg, keys.PageDown):
+		return m, m.moveBrowserSelection(m.browserListRows())
+	case key.Matches(msg, keys.Top):
+		return m, m.moveBrowserSelection(-len(b.Entries))
+	case key.Matches(msg, keys.Bottom):
+		return m, m.moveBrowserSelection(len(b.Entries))
+	case key.Matches(msg, keys.Open):
+		return m, m.openBrowserSelection()
+	case key.Matches(msg, keys.Filter):
+		b.Filtering = true
+	case key.Matches(msg, keys.Back):
+		if b.Filter != "" {
+			b.Filter = ""
+			return m, m.resetBrowserSelection()
+		}
+	case key.Matches(msg, keys.Sort):
+		// Keep the highlighted file selected across the re-sort
+		entry, _ := b.selectedEntry()
+		b.Sort = b.Sort.next()
+		b.sortEntries()
+		b.Selected = max(0, slices.IndexFunc(b.visible(), func(e browserEntry) bool {
+			return e.Path == entry.Path
+		}))
+		m.scrollBrowser()
+	case key.Matches(msg, keys.Quit, keys.ForceQuit):
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// browserEntryAt returns the visible entry on terminal row y
+func (m model) browserEntryAt(x, y int) (int, bool) {
+	if x >= m.browserListWidth() || y < 1 || y > m.browserListRows() {
+		return 0, false
+	}
+
+	i := m.Browser.Offset + y - 1
+	return i, i < len(m.Browser.visible())
+}
+
+func (m *model) handleBrowserClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
+	i, ok := m.browserEntryAt(msg.X, msg.Y)
+	if !ok || msg.Button != tea.MouseLeft {
+		return m, nil
+	}
+
+	// Clicking the highlighted file opens it
+	if i == m.Browser.Selected {
+		return m, m.openBrowserSelection()
+	}
+	return m, m.moveBrowserSelection(i - m.Browser.Selected)
+}
+
+func (m *model) handleBrowserWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseWheelUp:
+		return m, m.moveBrowserSelection(-1)
+	case tea.MouseWheelDown:
+		return m, m.moveBrowserSelection(1)
+	}
+	return m, nil
+}
+
+// browserRow formats one entry to fit width cells
+func browserRow(entry browserEntry, width int, selected bool) string {
+	marker := "  "
+	if selected {
+		marker = "▶ "
+	}
+
+	date := entry.ModTime.Format("Jan _2 15:04")
+	nameWidth := max(1, width-len(marker)-len(date)-11)
+	name := ansi.Truncate(entry.Name, nameWidth, "…")
+
+	return fmt.Sprintf("%s%-*s %9s %s", marker, nameWidth, name, formatBytes(entry.Size), date)
+}
+
+func (m model) renderBrowser() *lipgloss.Layer {
+	b := m.Browser
+	keys := m.keyMap()
+	entries := b.visible()
+	listWidth := m.browserListWidth()
+
+	label := m.colors().label()
+	highlight := m.colors().title()
+
+	header := fmt.Sprintf(" %s  %d/%d files  sort: %s", b.Dir, len(entries), len(b.Entries), b.Sort)
+	if b.Filter != "" || b.Filtering {
+		header += "  filter: " + b.Filter
+		if b.Filtering {
+			header += "█"
+		}
+	}
+
+	var rows []string
+	end := min(len(entries), b.Offset+m.browserListRows())
+	for i := b.Offset; i < end; i++ {
+		row := browserRow(entries[i], listWidth, i == b.Selected)
+		if i == b.Selected {
+			rows = append(rows, highlight.Render(row))
+		} else {
+			rows = append(rows, row)
+		}
+	}
+	if len(entries) == 0 {
+		message := "  No GIF files in " + b.Dir
+		if b.Filter != "" {
+			message = "  No files match " + b.Filter
+		}
+		rows = append(rows, label.Render(message))
+	}
+
+	footer := " " + keyHints(
+		keyHint(keys.Up, "up"),
+		keyHint(keys.Down, "down"),
+		keyHint(keys.Open, "open"),
+		keyHint(keys.Filter, "filter"),
+		keyHint(keys.Sort, "sort"),
+		keyHint(keys.Quit, "quit"),
+	) + " "
+	layers := []*lipgloss.Layer{
+		lipgloss.NewLayer(label.Render(ansi.Truncate(header, m.Width, "…"))).X(0).Y(0).Z(5),
+		lipgloss.NewLayer(strings.Join(rows, "\n")).X(0).Y(1).Z(0),
+		lipgloss.NewLayer(label.Render(footer)).X(0).Y(max(0, m.Height-1)).Z(5),
+	}
+
+	if width, height := m.browserPreviewSize(); width > 0 {
+		layers = append(layers, lipgloss.NewLayer(m.renderBrowserPreview(width, height)).
+			X(listWidth+1).
+			Y(1).
+			Z(1))
+	}
+
+	return lipgloss.NewLayer(lipgloss.NewCanvas(layers...).Render())
+}
+
+// renderBrowserPreview draws the highlighted file animating in a border
+func (m model) renderBrowserPreview(width, height int) string {
+	content := ""
+	if entry, ok := m.Browser.selectedEntry(); ok {
+		preview := m.Browser.Previews[entry.Path]
+		switch {
+		case preview == nil || preview.Loading:
+			content = "Loading preview..."
+		case preview.Err != nil:
+			content = preview.Err.Error()
+		default:
+			content = strings.TrimSuffix(preview.view(), "\n")
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(width + 2).
+		Height(height + 2).
+		AlignHorizontal(lipgloss.Center).
+		AlignVertical(lipgloss.Center).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.colors().Muted).
+		Render(content)
+}
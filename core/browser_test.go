@@ -0,0 +1,240 @@
+package jif
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"", "cat.gif", true},
+		{"cat", "cat.gif", true},
+		{"cgf", "cat.gif", true},
+		{"CAT", "cat.gif", true},
+		{"tac", "cat.gif", false},
+		{"dog", "cat.gif", false},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+// newTestBrowser creates a directory with GIFs of different sizes and ages
+func newTestBrowser(t *testing.T) *browser {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"beta.gif", 300, 2 * time.Hour},
+		{"Alpha.gif", 100, time.Hour},
+		{"gamma.gif", 200, 3 * time.Hour},
+		{"notes.txt", 500, 0},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(-f.age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := newBrowser(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func entryNames(entries []browserEntry) []string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	return names
+}
+
+func TestBrowserSort(t *testing.T) {
+	tests := []struct {
+		sort browserSort
+		want []string
+	}{
+		{sortByName, []string{"Alpha.gif", "beta.gif", "gamma.gif"}},
+		{sortBySize, []string{"beta.gif", "gamma.gif", "Alpha.gif"}},
+		{sortByDate, []string{"Alpha.gif", "beta.gif", "gamma.gif"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			b := newTestBrowser(t)
+			b.Sort = tt.sort
+			b.sortEntries()
+
+			got := entryNames(b.Entries)
+			if len(got) != len(tt.want) {
+				t.Fatalf("entries = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entries = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestBrowserKeys(t *testing.T) {
+	m := &model{Width: 100, Height: 20, Browser: newTestBrowser(t), ShowBrowser: true}
+
+	m.Update(tea.KeyPressMsg{Code: 'j', Text: "j"})
+	if m.Browser.Selected != 1 {
+		t.Errorf("Selected = %d after j, want 1", m.Browser.Selected)
+	}
+
+	// Sorting keeps the highlighted file
+	m.Update(tea.KeyPressMsg{Code: 's', Text: "s"})
+	if entry, _ := m.Browser.selectedEntry(); m.Browser.Sort != sortBySize || entry.Name != "beta.gif" {
+		t.Errorf("after s: sort %v, selected %s, want size, beta.gif", m.Browser.Sort, entry.Name)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	for _, r := range "gm" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if got := entryNames(m.Browser.visible()); m.Browser.Filter != "gm" || len(got) != 1 || got[0] != "gamma.gif" {
+		t.Errorf("filter %q lists %v, want gamma.gif", m.Browser.Filter, got)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.Browser.Filter != "" || m.Browser.Filtering {
+		t.Errorf("esc left filter %q, filtering %v", m.Browser.Filter, m.Browser.Filtering)
+	}
+}
+
+func TestOpenBrowserSelection(t *testing.T) {
+	b := newTestBrowser(t)
+	b.Filter = "a"
+	b.Selected = 1
+
+	m := &model{Width: 100, Height: 20, Browser: b, ShowBrowser: true}
+	if m.openBrowserSelection() == nil {
+		t.Fatal("openBrowserSelection() returned no load command")
+	}
+
+	if m.ShowBrowser {
+		t.Error("ShowBrowser still set after opening a file")
+	}
+	if got := len(m.Playlist); got != 3 {
+		t.Errorf("len(Playlist) = %d, want 3", got)
+	}
+	if m.pendingSource != filepath.Join(b.Dir, "beta.gif") {
+		t.Errorf("pendingSource = %q, want beta.gif", m.pendingSource)
+	}
+
+	// q in the viewer goes back to the browser rather than quitting
+	m.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	if !m.ShowBrowser {
+		t.Error("q did not return to the browser")
+	}
+}
+
+func TestBrowseDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.gif")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		opts Options
+		want bool
+	}{
+		{"no arguments", nil, Options{}, true},
+		{"single directory", []string{dir}, Options{}, true},
+		{"single file", []string{file}, Options{}, false},
+		{"several directories", []string{dir, dir}, Options{}, false},
+		{"grid plays the directory", []string{dir}, Options{Grid: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := browseDir(tt.args, tt.opts); got != tt.want {
+				t.Errorf("browseDir(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrunePreviews(t *testing.T) {
+	b := newTestBrowser(t)
+	m := &model{Width: 100, Height: 20, Browser: b, ShowBrowser: true}
+	for _, entry := range b.Entries {
+		b.Previews[entry.Path] = &player{Source: entry.Path, GIF: &gif.GIF{Delay: []int{10}}, Frames: []string{"frame"}}
+	}
+
+	// Filtering hides two of the three files
+	m.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	for _, r := range "gm" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if _, ok := b.Previews[filepath.Join(b.Dir, "gamma.gif")]; !ok || len(b.Previews) != 1 {
+		t.Errorf("kept %d previews, want only gamma.gif", len(b.Previews))
+	}
+
+	// Scrolling the list drops the rows that left the screen
+	b.Filter, b.Filtering = "", false
+	m.Height = 3
+	m.moveBrowserSelection(2)
+	if _, ok := b.Previews[filepath.Join(b.Dir, "gamma.gif")]; !ok || len(b.Previews) != 1 {
+		t.Errorf("kept %d previews after scrolling, want only gamma.gif", len(b.Previews))
+	}
+}
+
+func TestBrowserFilterUsesBindings(t *testing.T) {
+	keys, err := newKeyMap("media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys.Open.SetKeys("tab")
+	m := &model{Width: 100, Height: 20, Browser: newTestBrowser(t), ShowBrowser: true, keys: keys}
+	b := m.Browser
+
+	m.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	m.Update(tea.KeyPressMsg{Code: 'g', Text: "g"})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if b.Filtering || b.Filter != "g" {
+		t.Fatalf("the Open binding left filter %q, filtering %v, want g kept", b.Filter, b.Filtering)
+	}
+
+	// Backspace edits the filter first and only then acts as Back
+	m.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	if !b.Filtering || b.Filter != "" {
+		t.Fatalf("backspace left filter %q, filtering %v, want an empty filter", b.Filter, b.Filtering)
+	}
+	m.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	if b.Filtering {
+		t.Error("the Back binding did not leave the filter")
+	}
+}
@@ -0,0 +1,152 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// defaultCatWidth is the output width when it is neither given nor known
+// from the terminal
+const defaultCatWidth = 80
+
+// ============================================================================
+// Frame Selection
+// ============================================================================
+
+// frameAtTime returns the frame on screen at d into playback, wrapping around
+// as the animation loops
+func frameAtTime(g *gif.GIF, d time.Duration) int {
+	if total := totalDuration(g); total > 0 {
+		d %= total
+	}
+	for i := range g.Image {
+		delay := frameDelay(g, i)
+		if d < delay {
+			return i
+		}
+		d -= delay
+	}
+	return len(g.Image) - 1
+}
+
+// selectFrame returns the 0-based frame chosen by a 1-based frame number or a
+// playback time, defaulting to the first frame
+func selectFrame(g *gif.GIF, frame int, at time.Duration) (int, error) {
+	switch {
+	case frame != 0 && at != 0:
+		return 0, fmt.Errorf("choose a frame or a time, not both")
+	case frame < 0 || frame > len(g.Image):
+		return 0, fmt.Errorf("frame %d out of range (1-%d)", frame, len(g.Image))
+	case at < 0:
+		return 0, fmt.Errorf("invalid time %v", at)
+	case frame > 0:
+		return frame - 1, nil
+	}
+	return frameAtTime(g, at), nil
+}
+
+// compositedFrame returns frame i of g with the frames before it applied
+func compositedFrame(g *gif.GIF, i int) *image.RGBA {
+	var frame *image.RGBA
+	compositeFrames(g, func(j int, img *image.RGBA) {
+		if j == i {
+			frame = img
+		}
+	})
+	return frame
+}
+
+// ============================================================================
+// Cat Mode
+// ============================================================================
+
+// outputWidth returns width, or when it is 0 the terminal width, falling back
+// to 80 columns when stdout is not a terminal
+func outputWidth(width int) int {
+	if width != 0 {
+		return width
+	}
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 {
+		return w
+	}
+	return defaultCatWidth
+}
+
+// catModel returns a model that renders frames Width columns wide, and Height
+// rows tall or as tall as the image needs when Height is 0
+func catModel(opts Options, img image.Image) (model, error) {
+	fit, err := parseFitMode(opts.Fit)
+	if err != nil {
+		return model{}, err
+	}
+	cellAspect, err := parseCellAspect(opts.CellAspect)
+	if err != nil {
+		return model{}, err
+	}
+	bg, err := parseBackground(opts.Background)
+	if err != nil {
+		return model{}, err
+	}
+	if opts.Width < 0 || opts.Height < 0 {
+		return model{}, fmt.Errorf("invalid size %dx%d", opts.Width, opts.Height)
+	}
+
+	width := outputWidth(opts.Width)
+	if cellAspect == 0 {
+		cellAspect = queryCellAspect()
+	}
+
+	m := model{Width: width, Height: opts.Height, Fit: fit, CellAspect: cellAspect, Background: bg}
+	if m.Height == 0 {
+		// Size to the width alone, as contain would with unlimited rows
+		sizing := m
+		sizing.Fit = fitContain
+		sizing.Height = 1 << 16
+		_, h := sizing.calculateImageSize(img)
+		m.Height = max(1, (h+1)/2)
+	}
+	return m, nil
+}
+
+// Cat writes one frame of each source to w as halfblock ANSI text, without
+// starting the interactive viewer. Options.Frame or Options.Time chooses the
+// frame and Options.Width and Options.Height the size.
+func Cat(w io.Writer, args []string, opts Options) error {
+	w, err := outputWriter(w, opts.Color)
+	if err != nil {
+		return err
+	}
+
+	sources, err := expandSources(args)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		g, _, err := loadGIFWithStats(source)
+		if err != nil {
+			return fmt.Errorf("loading GIF: %w", err)
+		}
+
+		i, err := selectFrame(g, opts.Frame, opts.Time)
+		if err != nil {
+			return fmt.Errorf("choosing frame: %w", err)
+		}
+
+		img := compositedFrame(g, i)
+		m, err := catModel(opts, img)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, m.renderImageHalfBlock(img, nil)); err != nil {
+			return fmt.Errorf("writing frame: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package jif
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSelectFrame(t *testing.T) {
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, 3),
+		Delay: []int{10, 20, 30},
+	}
+
+	tests := []struct {
+		name    string
+		frame   int
+		at      time.Duration
+		want    int
+		wantErr bool
+	}{
+		{name: "default first", want: 0},
+		{name: "frame number", frame: 3, want: 2},
+		{name: "time in first frame", at: 50 * time.Millisecond, want: 0},
+		{name: "time at second frame", at: 100 * time.Millisecond, want: 1},
+		{name: "time in third frame", at: 400 * time.Millisecond, want: 2},
+		{name: "time wraps around", at: 650 * time.Millisecond, want: 0},
+		{name: "frame out of range", frame: 4, wantErr: true},
+		{name: "negative time", at: -time.Second, wantErr: true},
+		{name: "frame and time", frame: 1, at: time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := selectFrame(g, tt.frame, tt.at)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: selectFrame() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: selectFrame() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCat(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		wantLines int
+		wantWidth int
+	}{
+		{
+			name:      "fixed size",
+			opts:      Options{Width: 20, Height: 5, Fit: "stretch", CellAspect: 2},
+			wantLines: 5,
+			wantWidth: 20,
+		},
+		{
+			// simple.gif is square, so 30 columns of square pixels need 15 rows
+			name:      "height from width",
+			opts:      Options{Width: 30, CellAspect: 2},
+			wantLines: 15,
+			wantWidth: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Cat(&buf, []string{"../testdata/simple.gif"}, tt.opts); err != nil {
+				t.Fatalf("Cat() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != tt.wantLines {
+				t.Errorf("Cat() printed %d lines, want %d", len(lines), tt.wantLines)
+			}
+			if w := ansi.StringWidth(lines[0]); w != tt.wantWidth {
+				t.Errorf("Cat() line width = %d, want %d", w, tt.wantWidth)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := Cat(&buf, []string{"../testdata/simple.gif"}, Options{Frame: 99}); err == nil {
+		t.Error("Cat() with a frame out of range expected an error")
+	}
+}
@@ -0,0 +1,88 @@
+package jif
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// defaultCellAspect is the assumed cell height/width ratio when the terminal
+// does not report its cell size
+const defaultCellAspect = 2.0
+
+// requestCellSizeWinOp asks the terminal to report its cell size in pixels
+// (CSI 16 t), answered with CSI 6 ; height ; width t
+const requestCellSizeWinOp = 16
+
+// ============================================================================
+// Cell Geometry
+// ============================================================================
+
+// cellAspect returns the height/width ratio of a terminal cell
+func (m model) cellAspect() float64 {
+	if m.CellAspect > 0 {
+		return m.CellAspect
+	}
+	return defaultCellAspect
+}
+
+// charsPerPixel returns how many characters each pixel column spans. A
+// halfblock pixel is aspect/2 times as tall as it is wide when drawn with one
+// character and aspect/4 with two, so pick whichever is closer to square.
+func (m model) charsPerPixel() int {
+	if m.PixelChars > 0 {
+		return m.PixelChars
+	}
+	if m.cellAspect() < 8.0/3.0 {
+		return 1
+	}
+	return 2
+}
+
+// pixelStretch returns how many pixel rows are needed per pixel column to
+// keep a square source pixel square on screen
+func (m model) pixelStretch() float64 {
+	return float64(2*m.charsPerPixel()) / m.cellAspect()
+}
+
+// requestCellSize queries the terminal cell size in pixels
+func requestCellSize() tea.Cmd {
+	return tea.Raw(ansi.WindowOp(requestCellSizeWinOp))
+}
+
+// handleCellSize applies a cell size reported by the terminal unless the user
+// fixed the aspect ratio on the command line
+func (m *model) handleCellSize(msg uv.CellSizeEvent) (tea.Model, tea.Cmd) {
+	if m.cellAspectFixed || msg.Width <= 0 || msg.Height <= 0 {
+		return m, nil
+	}
+
+	aspect := float64(msg.Height) / float64(msg.Width)
+
+	// Frames being rendered keep the old aspect, which clicks are mapped
+	// with, until they are rendered again for the new one
+	if m.Loading {
+		m.pendingCellAspect = aspect
+		m.reprocessPending = m.reprocessPending || aspect != m.CellAspect
+		return m, nil
+	}
+
+	if aspect == m.CellAspect {
+		return m, nil
+	}
+	m.CellAspect = aspect
+	if !m.Ready {
+		return m, nil
+	}
+	return m, m.reprocess()
+}
+
+// parseCellAspect validates a --cell-aspect value; 0 means detect
+func parseCellAspect(aspect float64) (float64, error) {
+	if aspect < 0 || aspect > 10 {
+		return 0, fmt.Errorf("invalid cell aspect %g (want a height/width ratio between 0 and 10)", aspect)
+	}
+	return aspect, nil
+}
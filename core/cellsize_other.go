@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !aix && !zos
+
+package jif
+
+// queryCellAspect is unsupported on this platform; the terminal is asked via
+// CSI 16 t instead
+func queryCellAspect() float64 {
+	return 0
+}
@@ -0,0 +1,138 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCharsPerPixel(t *testing.T) {
+	tests := []struct {
+		name       string
+		aspect     float64
+		pixelChars int
+		want       int
+	}{
+		{"default aspect uses one char", 0, 0, 1},
+		{"typical font uses one char", 2.2, 0, 1},
+		{"very tall cells use two chars", 4, 0, 2},
+		{"explicit override wins", 2, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{CellAspect: tt.aspect, PixelChars: tt.pixelChars}
+			if got := m.charsPerPixel(); got != tt.want {
+				t.Errorf("charsPerPixel() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateImageSizeCellAspect(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+
+	tests := []struct {
+		name       string
+		aspect     float64
+		wantWidth  int
+		wantHeight int
+	}{
+		// One char per pixel on 1:2 cells gives square pixels
+		{"square pixels", 2, 50, 50},
+		// Taller cells need fewer pixel rows for the same height
+		{"tall cells", 2.5, 50, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model{Width: 50, Height: 50, CellAspect: tt.aspect}
+			gotWidth, gotHeight := m.calculateImageSize(img)
+			if gotWidth != tt.wantWidth || gotHeight != tt.wantHeight {
+				t.Errorf("calculateImageSize() = %dx%d, want %dx%d", gotWidth, gotHeight, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestRenderOneCharPerPixel(t *testing.T) {
+	m := &model{Width: 20, Height: 10}
+
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+
+	result := ansi.Strip(m.renderImageHalfBlock(img, nil))
+	for i, line := range strings.Split(strings.TrimRight(result, "\n"), "\n") {
+		if w := ansi.StringWidth(line); w != 20 {
+			t.Errorf("line %d is %d cells wide, want 20", i, w)
+		}
+	}
+}
+
+func TestHandleCellSize(t *testing.T) {
+	t.Run("detected size re-renders", func(t *testing.T) {
+		m := &model{
+			player: player{GIF: &gif.GIF{Delay: []int{10}}, Frames: []string{"frame1"}},
+			Ready:  true,
+		}
+
+		_, cmd := m.Update(uv.CellSizeEvent{Width: 10, Height: 25})
+		if m.CellAspect != 2.5 {
+			t.Errorf("CellAspect = %v, want 2.5", m.CellAspect)
+		}
+		if cmd == nil || m.Ready {
+			t.Error("a new cell size should re-render the frames")
+		}
+	})
+
+	t.Run("size reported while rendering waits for the render", func(t *testing.T) {
+		m := &model{
+			player:     player{GIF: &gif.GIF{Delay: []int{10}}, Frames: []string{"frame1"}, Loading: true},
+			CellAspect: 2,
+		}
+
+		if _, cmd := m.Update(uv.CellSizeEvent{Width: 10, Height: 25}); cmd != nil || m.CellAspect != 2 {
+			t.Fatalf("CellAspect = %v while rendering, want 2 until the render ends", m.CellAspect)
+		}
+
+		_, cmd := m.Update(processingCompleteMsg{})
+		if m.CellAspect != 2.5 || cmd == nil || !m.Loading {
+			t.Errorf("after the render: CellAspect = %v, loading %v, want 2.5 and a new render", m.CellAspect, m.Loading)
+		}
+	})
+
+	t.Run("command line override is kept", func(t *testing.T) {
+		m := &model{
+			Ready:           true,
+			CellAspect:      2.1,
+			cellAspectFixed: true,
+		}
+
+		_, cmd := m.Update(uv.CellSizeEvent{Width: 10, Height: 25})
+		if m.CellAspect != 2.1 || cmd != nil {
+			t.Errorf("fixed aspect should be kept, got %v", m.CellAspect)
+		}
+	})
+}
+
+func TestParseCellAspect(t *testing.T) {
+	for _, aspect := range []float64{0, 1.8, 2} {
+		if _, err := parseCellAspect(aspect); err != nil {
+			t.Errorf("parseCellAspect(%v) error = %v", aspect, err)
+		}
+	}
+	for _, aspect := range []float64{-1, 11} {
+		if _, err := parseCellAspect(aspect); err == nil {
+			t.Errorf("parseCellAspect(%v) should fail", aspect)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || aix || zos
+
+package jif
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// queryCellAspect reads the cell size from the pixel fields of TIOCGWINSZ,
+// returning 0 when the terminal does not fill them in
+func queryCellAspect() float64 {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Xpixel == 0 || ws.Ypixel == 0 || ws.Col == 0 || ws.Row == 0 {
+		return 0
+	}
+
+	cellWidth := float64(ws.Xpixel) / float64(ws.Col)
+	cellHeight := float64(ws.Ypixel) / float64(ws.Row)
+	return cellHeight / cellWidth
+}
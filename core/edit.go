@@ -0,0 +1,355 @@
+package jif
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+// resizeFilters maps filter names to the interpolation used when scaling
+var resizeFilters = map[string]resize.InterpolationFunction{
+	"nearest":  resize.NearestNeighbor,
+	"bilinear": resize.Bilinear,
+	"bicubic":  resize.Bicubic,
+	"mitchell": resize.MitchellNetravali,
+	"lanczos2": resize.Lanczos2,
+	"lanczos3": resize.Lanczos3,
+}
+
+// resizeFilterNames lists the filters in order of quality
+var resizeFilterNames = []string{"nearest", "bilinear", "bicubic", "mitchell", "lanczos2", "lanczos3"}
+
+// EditOptions describes the changes Edit makes to a GIF; zero values leave
+// that aspect unchanged. They are applied in field order.
+type EditOptions struct {
+	// Range keeps 1-based inclusive frames, e.g. 10:20, 10: or 5
+	Range string
+
+	// Crop keeps a WxH+X+Y rectangle of the canvas, or WxH from the top left
+	Crop string
+
+	// Scale resizes to WxH, keeping the aspect ratio when either side is
+	// left out (320x or x240), or by a percentage such as 50%. Filter names
+	// the interpolation: nearest, bilinear, bicubic, mitchell, lanczos2 or
+	// lanczos3. Empty means lanczos3.
+	Scale  string
+	Filter string
+
+	// Reverse plays the frames backwards
+	Reverse bool
+
+	// Delay gives every frame the same delay, then Speed multiplies the
+	// playback rate
+	Delay time.Duration
+	Speed float64
+
+	// Loop is forever or a number of plays. Empty keeps the file's setting.
+	Loop string
+
+	// Optimize stores only the changed part of each frame, merges repeated
+	// frames and picks each frame's disposal. Colors limits the palette to
+	// that many colours; 0 keeps as many as a frame can hold.
+	Optimize bool
+	Colors   int
+}
+
+// EditStats reports what an edit wrote
+type EditStats struct {
+	Frames int   // frames written
+	Before int64 // size of the source in bytes, 0 when unknown
+	After  int64 // size of the written GIF in bytes
+}
+
+// String summarises the sizes, e.g. "12 frames, 1.2 MiB -> 310.4 KiB (75% smaller)"
+func (s EditStats) String() string {
+	summary := fmt.Sprintf("%d frames, ", s.Frames)
+	if s.Before <= 0 {
+		return summary + formatBytes(s.After)
+	}
+	summary += fmt.Sprintf("%s -> %s", formatBytes(s.Before), formatBytes(s.After))
+	change := 100 * float64(s.After-s.Before) / float64(s.Before)
+	switch {
+	case change < 0:
+		return summary + fmt.Sprintf(" (%.0f%% smaller)", -change)
+	case change > 0:
+		return summary + fmt.Sprintf(" (%.0f%% larger)", change)
+	}
+	return summary
+}
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+// parseSize parses WxH where either side may be left out or 0
+func parseSize(s string) (w, h int, err error) {
+	ws, hs, ok := strings.Cut(strings.ToLower(s), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size %q (want WxH)", s)
+	}
+	side := func(text string) (int, error) {
+		if text == "" {
+			return 0, nil
+		}
+		v, err := strconv.Atoi(text)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid size %q (want WxH)", s)
+		}
+		return v, nil
+	}
+	if w, err = side(ws); err != nil {
+		return 0, 0, err
+	}
+	if h, err = side(hs); err != nil {
+		return 0, 0, err
+	}
+	return w, h, nil
+}
+
+// parseCrop parses a WxH+X+Y rectangle, or WxH at the origin, and checks
+// that it lies within bounds
+func parseCrop(s string, bounds image.Rectangle) (image.Rectangle, error) {
+	size, offset, _ := strings.Cut(s, "+")
+	w, h, err := parseSize(size)
+	if err != nil || w == 0 || h == 0 {
+		return image.Rectangle{}, fmt.Errorf("invalid crop %q (want WxH+X+Y)", s)
+	}
+
+	var x, y int
+	if offset != "" {
+		xs, ys, ok := strings.Cut(offset, "+")
+		if x, err = strconv.Atoi(xs); !ok || err != nil {
+			return image.Rectangle{}, fmt.Errorf("invalid crop %q (want WxH+X+Y)", s)
+		}
+		if y, err = strconv.Atoi(ys); err != nil {
+			return image.Rectangle{}, fmt.Errorf("invalid crop %q (want WxH+X+Y)", s)
+		}
+	}
+
+	rect := image.Rect(x, y, x+w, y+h)
+	if !rect.In(bounds) {
+		return image.Rectangle{}, fmt.Errorf("crop %v is outside the %dx%d canvas", rect, bounds.Dx(), bounds.Dy())
+	}
+	return rect, nil
+}
+
+// parseScale returns the size to scale a w x h image to
+func parseScale(s string, w, h int) (int, int, error) {
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		p, err := strconv.ParseFloat(pct, 64)
+		if err != nil || p <= 0 {
+			return 0, 0, fmt.Errorf("invalid scale %q (want WxH or a percentage)", s)
+		}
+		return max(1, int(math.Round(float64(w)*p/100))), max(1, int(math.Round(float64(h)*p/100))), nil
+	}
+
+	sw, sh, err := parseSize(s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid scale %q (want WxH or a percentage)", s)
+	}
+	switch {
+	case sw == 0 && sh == 0:
+		return 0, 0, fmt.Errorf("invalid scale %q (want WxH or a percentage)", s)
+	case sw == 0:
+		sw = max(1, int(math.Round(float64(w)*float64(sh)/float64(h))))
+	case sh == 0:
+		sh = max(1, int(math.Round(float64(h)*float64(sw)/float64(w))))
+	}
+	return sw, sh, nil
+}
+
+// parseResizeFilter returns the interpolation named by s
+func parseResizeFilter(s string) (resize.InterpolationFunction, error) {
+	if s == "" {
+		return resize.Lanczos3, nil
+	}
+	if f, ok := resizeFilters[strings.ToLower(s)]; ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown resize filter %q (want %s)", s, strings.Join(resizeFilterNames, ", "))
+}
+
+// loopCount converts a loop setting to a GIF loop count, keeping current
+// when it is empty
+func loopCount(loop string, current int) (int, error) {
+	if loop == "" {
+		return current, nil
+	}
+	parsed, err := parseLoop(loop)
+	if err != nil || parsed == loopGIF {
+		return 0, fmt.Errorf("invalid loop %q (want forever or a number of plays)", loop)
+	}
+	if parsed == loopForever {
+		return 0, nil
+	}
+
+	// The GIF loop count is the number of repeats; -1 plays once
+	plays, _ := strconv.Atoi(parsed)
+	if plays == 1 {
+		return -1, nil
+	}
+	return plays - 1, nil
+}
+
+// centiseconds converts d to a GIF delay, at least one hundredth
+func centiseconds(d time.Duration) int {
+	return max(1, int(math.Round(float64(d)/float64(10*time.Millisecond))))
+}
+
+// ============================================================================
+// Editing
+// ============================================================================
+
+// editGIF returns a new GIF with opts applied to g, leaving g untouched.
+// Frames are composited first, so every output frame is a full canvas.
+func editGIF(g *gif.GIF, opts EditOptions) (*gif.GIF, error) {
+	start, end, err := parseFrameRange(opts.Range, len(g.Image))
+	if err != nil {
+		return nil, err
+	}
+	filter, err := parseResizeFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	speed, err := parseSpeed(opts.Speed)
+	if err != nil {
+		return nil, err
+	}
+	loops, err := loopCount(opts.Loop, g.LoopCount)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Delay < 0 {
+		return nil, fmt.Errorf("invalid delay %v", opts.Delay)
+	}
+	if opts.Colors != 0 && (opts.Colors < 2 || opts.Colors > maxPaletteSize) {
+		return nil, fmt.Errorf("invalid colour count %d (want 2 to %d)", opts.Colors, maxPaletteSize)
+	}
+
+	var frames []image.Image
+	var delays []time.Duration
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		if i >= start && i < end {
+			frames = append(frames, img)
+			delays = append(delays, frameDelay(g, i))
+		}
+	})
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("GIF has no frames")
+	}
+
+	if opts.Crop != "" {
+		rect, err := parseCrop(opts.Crop, frames[0].Bounds())
+		if err != nil {
+			return nil, err
+		}
+		for i, img := range frames {
+			cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+			draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+			frames[i] = cropped
+		}
+	}
+
+	if opts.Scale != "" {
+		bounds := frames[0].Bounds()
+		w, h, err := parseScale(opts.Scale, bounds.Dx(), bounds.Dy())
+		if err != nil {
+			return nil, err
+		}
+		for i, img := range frames {
+			frames[i] = resize.Resize(uint(w), uint(h), img, filter)
+		}
+	}
+
+	if opts.Reverse {
+		slices.Reverse(frames)
+		slices.Reverse(delays)
+	}
+
+	out := &gif.GIF{LoopCount: loops}
+	gifDelays := make([]int, len(frames))
+	for i, delay := range delays {
+		if opts.Delay > 0 {
+			delay = opts.Delay
+		}
+		gifDelays[i] = centiseconds(time.Duration(float64(delay) / speed))
+	}
+	if opts.Optimize {
+		optimizeFrames(out, frames, gifDelays, opts.Colors)
+		return out, nil
+	}
+
+	colors := cmp.Or(opts.Colors, maxPaletteSize)
+	for _, img := range frames {
+		out.Image = append(out.Image, quantize(img, colors))
+	}
+	out.Delay = gifDelays
+
+	// Each frame is a full canvas, so it only needs clearing first when
+	// transparent pixels would otherwise show the frame before
+	disposal := byte(gif.DisposalNone)
+	for _, img := range out.Image {
+		if transparentIndex(img.Palette) >= 0 {
+			disposal = gif.DisposalBackground
+			break
+		}
+	}
+	for range out.Image {
+		out.Disposal = append(out.Disposal, disposal)
+	}
+	return out, nil
+}
+
+// writeGIF encodes g to path and returns the size written
+func writeGIF(path string, g *gif.GIF) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := gif.EncodeAll(f, g); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("encoding %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	return info.Size(), f.Close()
+}
+
+// saveEdited applies opts to g and writes the result to out; before is the
+// size of the source, if known
+func saveEdited(g *gif.GIF, out string, opts EditOptions, before int64) (EditStats, error) {
+	edited, err := editGIF(g, opts)
+	if err != nil {
+		return EditStats{}, err
+	}
+	after, err := writeGIF(out, edited)
+	if err != nil {
+		return EditStats{}, err
+	}
+	return EditStats{Frames: len(edited.Image), Before: before, After: after}, nil
+}
+
+// Edit reads source, applies opts and writes the result to out as a new GIF.
+// Frames are composited and re-encoded: as full frames with their own
+// palettes, or with Optimize as the changed areas only. It reports the sizes
+// before and after.
+func Edit(source, out string, opts EditOptions) (EditStats, error) {
+	g, stats, err := loadGIFWithStats(source)
+	if err != nil {
+		return EditStats{}, fmt.Errorf("loading GIF: %w", err)
+	}
+	return saveEdited(g, out, opts, stats.FileSize)
+}
@@ -0,0 +1,229 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newEditTestGIF returns a 4x2 GIF whose frame i is filled with grey level
+// i*50 and shown for (i+1) hundredths
+func newEditTestGIF(frames int) *gif.GIF {
+	g := &gif.GIF{LoopCount: 0}
+	for i := range frames {
+		level := uint8(i * 50)
+		img := image.NewPaletted(image.Rect(0, 0, 4, 2), color.Palette{color.Gray{Y: level}})
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, i+1)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	return g
+}
+
+// frameLevel returns the grey level of the top-left pixel of frame i
+func frameLevel(g *gif.GIF, i int) uint8 {
+	return color.GrayModel.Convert(g.Image[i].At(0, 0)).(color.Gray).Y
+}
+
+func TestParseCrop(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 50)
+
+	tests := []struct {
+		in      string
+		want    image.Rectangle
+		wantErr bool
+	}{
+		{in: "40x20+10+5", want: image.Rect(10, 5, 50, 25)},
+		{in: "40x20", want: image.Rect(0, 0, 40, 20)},
+		{in: "100x50+0+0", want: bounds},
+		{in: "40x20+70+0", wantErr: true},
+		{in: "0x20", wantErr: true},
+		{in: "40x20+5", wantErr: true},
+		{in: "forty", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseCrop(tt.in, bounds)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCrop(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCrop(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseScale(t *testing.T) {
+	tests := []struct {
+		in           string
+		wantW, wantH int
+		wantErr      bool
+	}{
+		{in: "50%", wantW: 100, wantH: 50},
+		{in: "320x", wantW: 320, wantH: 160},
+		{in: "x50", wantW: 100, wantH: 50},
+		{in: "30x40", wantW: 30, wantH: 40},
+		{in: "x", wantErr: true},
+		{in: "-10%", wantErr: true},
+		{in: "big", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		w, h, err := parseScale(tt.in, 200, 100)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseScale(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("parseScale(%q) = %dx%d, want %dx%d", tt.in, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestLoopCount(t *testing.T) {
+	tests := []struct {
+		loop    string
+		want    int
+		wantErr bool
+	}{
+		{loop: "", want: 3},
+		{loop: "forever", want: 0},
+		{loop: "1", want: -1},
+		{loop: "3", want: 2},
+		{loop: "gif", wantErr: true},
+		{loop: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := loopCount(tt.loop, 3)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("loopCount(%q) error = %v, wantErr %v", tt.loop, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("loopCount(%q) = %d, want %d", tt.loop, got, tt.want)
+		}
+	}
+}
+
+func TestEditGIF(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       EditOptions
+		wantLevels []uint8
+		wantDelays []int
+		wantSize   image.Point
+		wantLoop   int
+	}{
+		{
+			name:       "unchanged",
+			wantLevels: []uint8{0, 50, 100, 150},
+			wantDelays: []int{1, 2, 3, 4},
+			wantSize:   image.Pt(4, 2),
+		},
+		{
+			name:       "trim and reverse",
+			opts:       EditOptions{Range: "2:3", Reverse: true},
+			wantLevels: []uint8{100, 50},
+			wantDelays: []int{3, 2},
+			wantSize:   image.Pt(4, 2),
+		},
+		{
+			name:       "crop and scale",
+			opts:       EditOptions{Crop: "2x2+1+0", Scale: "300%", Filter: "nearest"},
+			wantLevels: []uint8{0, 50, 100, 150},
+			wantDelays: []int{1, 2, 3, 4},
+			wantSize:   image.Pt(6, 6),
+		},
+		{
+			name:       "normalise delays and speed up",
+			opts:       EditOptions{Delay: 80 * time.Millisecond, Speed: 2, Loop: "1"},
+			wantLevels: []uint8{0, 50, 100, 150},
+			wantDelays: []int{4, 4, 4, 4},
+			wantSize:   image.Pt(4, 2),
+			wantLoop:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newEditTestGIF(4)
+			got, err := editGIF(g, tt.opts)
+			if err != nil {
+				t.Fatalf("editGIF() error = %v", err)
+			}
+
+			if len(got.Image) != len(tt.wantLevels) {
+				t.Fatalf("editGIF() has %d frames, want %d", len(got.Image), len(tt.wantLevels))
+			}
+			for i, want := range tt.wantLevels {
+				if level := frameLevel(got, i); level != want {
+					t.Errorf("frame %d level = %d, want %d", i, level, want)
+				}
+				if got.Delay[i] != tt.wantDelays[i] {
+					t.Errorf("frame %d delay = %d, want %d", i, got.Delay[i], tt.wantDelays[i])
+				}
+			}
+			if size := got.Image[0].Bounds().Size(); size != tt.wantSize {
+				t.Errorf("frame size = %v, want %v", size, tt.wantSize)
+			}
+			if got.LoopCount != tt.wantLoop {
+				t.Errorf("LoopCount = %d, want %d", got.LoopCount, tt.wantLoop)
+			}
+
+			// The source is left alone
+			if len(g.Image) != 4 || frameLevel(g, 0) != 0 {
+				t.Errorf("editGIF() changed its input")
+			}
+		})
+	}
+}
+
+func TestEditGIFErrors(t *testing.T) {
+	tests := []EditOptions{
+		{Range: "3:9"},
+		{Crop: "9x9"},
+		{Scale: "huge"},
+		{Filter: "blur"},
+		{Speed: -1},
+		{Delay: -time.Second},
+		{Loop: "gif"},
+	}
+
+	for _, opts := range tests {
+		if _, err := editGIF(newEditTestGIF(4), opts); err == nil {
+			t.Errorf("editGIF(%+v) error = nil, want an error", opts)
+		}
+	}
+}
+
+func TestEdit(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "clip.gif")
+	stats, err := Edit("../testdata/multi.gif", out, EditOptions{Range: "1:3", Scale: "50%"})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if stats.Frames != 3 || stats.Before == 0 || stats.After == 0 {
+		t.Errorf("Edit() stats = %+v, want 3 frames and both sizes", stats)
+	}
+
+	g, err := loadGIF(out)
+	if err != nil {
+		t.Fatalf("reading edited GIF: %v", err)
+	}
+	src, err := loadGIF("../testdata/multi.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 {
+		t.Errorf("edited GIF has %d frames, want 3", len(g.Image))
+	}
+	w, h := getGifDimensions(src)
+	if got, want := g.Image[0].Bounds().Size(), image.Pt(w/2, h/2); got != want {
+		t.Errorf("edited GIF is %v, want %v", got, want)
+	}
+}
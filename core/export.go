@@ -0,0 +1,445 @@
+package jif
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// exportFormats lists the formats Export writes
+var exportFormats = []string{"asciicast", "html", "svg"}
+
+// SVG cell width in pixels; the height follows the cell aspect ratio
+const svgCellWidth = 8
+
+// ============================================================================
+// Export
+// ============================================================================
+
+// Export renders every frame of source and writes them to w in format.
+// Unlike the viewer the size never depends on the terminal, so the output
+// is the same for the same options: Options.Width defaults to 80 columns and
+// Options.CellAspect to 2. Frame delays are scaled by Options.Speed, and the
+// HTML and SVG animations play as often as Options.Loop says.
+func Export(w io.Writer, source, format string, opts Options) error {
+	if opts.Width == 0 {
+		opts.Width = defaultCatWidth
+	}
+	if opts.CellAspect == 0 {
+		opts.CellAspect = defaultCellAspect
+	}
+	speed, err := parseSpeed(opts.Speed)
+	if err != nil {
+		return err
+	}
+	loop, err := parseLoop(opts.Loop)
+	if err != nil {
+		return err
+	}
+
+	format = strings.ToLower(format)
+	switch format {
+	case "asciicast", "cast", "html", "htm", "svg":
+	case "":
+		return fmt.Errorf("no export format given (want %s)", strings.Join(exportFormats, ", "))
+	default:
+		return fmt.Errorf("unknown export format %q (want %s)", format, strings.Join(exportFormats, ", "))
+	}
+
+	g, _, err := loadGIFWithStats(source)
+	if err != nil {
+		return fmt.Errorf("loading GIF: %w", err)
+	}
+
+	if format == "asciicast" || format == "cast" {
+		frames, err := renderAllFrames(context.Background(), g, opts)
+		if err != nil {
+			return err
+		}
+		if frames, err = convertFrames(frames, opts.Color); err != nil {
+			return err
+		}
+		return writeAsciicast(w, g, frames, speed)
+	}
+
+	frames, m, err := renderAllCells(g, opts)
+	if err != nil {
+		return err
+	}
+	anim := animation{
+		Delays: make([]time.Duration, len(frames)),
+		Plays:  playLimit(loop, g),
+	}
+	for i := range frames {
+		anim.Delays[i] = playbackDelay(g, i, speed)
+	}
+
+	if format == "svg" {
+		return writeSVG(w, frames, anim, m.CellAspect)
+	}
+	return writeHTML(w, filepath.Base(source), frames, anim)
+}
+
+// convertFrames downsamples the colours of frames when a colour mode is
+// forced, since the exported escapes never pass through a terminal writer
+func convertFrames(frames []string, colorMode string) ([]string, error) {
+	var buf bytes.Buffer
+	w, err := outputWriter(&buf, colorMode)
+	if err != nil || w == io.Writer(&buf) {
+		return frames, err
+	}
+
+	converted := make([]string, len(frames))
+	for i, frame := range frames {
+		buf.Reset()
+		if _, err := io.WriteString(w, frame); err != nil {
+			return nil, fmt.Errorf("converting colours: %w", err)
+		}
+		converted[i] = buf.String()
+	}
+	return converted, nil
+}
+
+// frameSize returns the width and height of a rendered frame in cells
+func frameSize(frame string) (width, height int) {
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	return ansi.StringWidth(lines[0]), len(lines)
+}
+
+// ============================================================================
+// Cells
+// ============================================================================
+
+// halfBlock is one rendered cell: the colours of its top and bottom pixel,
+// nil where the pixel is transparent
+type halfBlock struct {
+	Top, Bottom color.Color
+}
+
+// cellGrid is a rendered frame as rows of cells
+type cellGrid [][]halfBlock
+
+// renderCells converts an image to the cells renderImageHalfBlock would draw,
+// with colours downsampled by convert when it is set
+func (m *model) renderCells(img image.Image, convert func(color.Color) color.Color) cellGrid {
+	scaled := m.scaleImage(img)
+	bounds := scaled.Bounds()
+	chars := m.charsPerPixel()
+
+	pixel := func(x, y int) color.Color {
+		if y >= bounds.Max.Y {
+			return nil
+		}
+		r, g, b, a := scaled.At(x, y).RGBA()
+		if a == 0 {
+			return nil
+		}
+		c := color.Color(color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 0xff})
+		if convert != nil {
+			c = convert(c)
+		}
+		return c
+	}
+
+	var grid cellGrid
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		var row []halfBlock
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			cell := halfBlock{Top: pixel(x, y), Bottom: pixel(x, y+1)}
+			for range chars {
+				row = append(row, cell)
+			}
+		}
+		grid = append(grid, row)
+	}
+	return grid
+}
+
+// renderAllCells renders every frame of g as cells, sized like Cat, and
+// returns the model that sized them
+func renderAllCells(g *gif.GIF, opts Options) ([]cellGrid, model, error) {
+	profile, forceProfile, err := parseColorMode(opts.Color)
+	if err != nil {
+		return nil, model{}, err
+	}
+	var convert func(color.Color) color.Color
+	if forceProfile {
+		convert = profile.Convert
+	}
+
+	var composited []*image.RGBA
+	compositeFrames(g, func(_ int, img *image.RGBA) {
+		composited = append(composited, img)
+	})
+	if len(composited) == 0 {
+		return nil, model{}, fmt.Errorf("GIF has no frames")
+	}
+
+	m, err := catModel(opts, composited[0])
+	if err != nil {
+		return nil, model{}, err
+	}
+
+	frames := make([]cellGrid, len(composited))
+	for i, img := range composited {
+		frames[i] = m.renderCells(img, convert)
+	}
+	return frames, m, nil
+}
+
+// ============================================================================
+// Animation
+// ============================================================================
+
+// animation is the timing of an exported HTML or SVG animation
+type animation struct {
+	Delays []time.Duration
+	Plays  int // 0 plays forever
+}
+
+// total returns the length of one play
+func (a animation) total() time.Duration {
+	var total time.Duration
+	for _, d := range a.Delays {
+		total += d
+	}
+	return total
+}
+
+// percent formats d as a keyframe offset into a play of length total
+func percent(d, total time.Duration) string {
+	p := strconv.FormatFloat(100*float64(d)/float64(total), 'f', 3, 64)
+	return strings.TrimSuffix(strings.TrimRight(p, "0"), ".") + "%"
+}
+
+// css returns a stylesheet that shows the element with class f and id f<i>
+// only while frame i is on screen; after the last play the last frame stays
+func (a animation) css() string {
+	total := a.total()
+	plays := "infinite"
+	if a.Plays > 0 {
+		plays = strconv.Itoa(a.Plays)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ".f{visibility:hidden;animation:%gs step-end %s forwards}\n", total.Seconds(), plays)
+
+	var start time.Duration
+	for i, delay := range a.Delays {
+		end := start + delay
+		fmt.Fprintf(&sb, "#f%d{animation-name:f%d}\n@keyframes f%d{", i, i, i)
+		if start > 0 {
+			fmt.Fprintf(&sb, "0%%{visibility:hidden}%s{visibility:visible}", percent(start, total))
+		} else {
+			sb.WriteString("0%{visibility:visible}")
+		}
+		if end < total {
+			fmt.Fprintf(&sb, "%s{visibility:hidden}100%%{visibility:hidden}", percent(end, total))
+		} else {
+			sb.WriteString("100%{visibility:visible}")
+		}
+		sb.WriteString("}\n")
+		start = end
+	}
+	return sb.String()
+}
+
+// ============================================================================
+// Asciicast
+// ============================================================================
+
+// asciicastHeader is the first line of an asciicast v2 file; the timestamp
+// is left out to keep the output deterministic
+type asciicastHeader struct {
+	Version int `json:"version"`
+	Width   int `json:"width"`
+	Height  int `json:"height"`
+}
+
+// writeAsciicast writes frames as an asciinema v2 recording, each one drawn
+// from the top left and shown for its GIF delay at speed
+func writeAsciicast(w io.Writer, g *gif.GIF, frames []string, speed float64) error {
+	width, height := frameSize(frames[0])
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(asciicastHeader{Version: 2, Width: width, Height: height}); err != nil {
+		return fmt.Errorf("writing asciicast: %w", err)
+	}
+
+	var at time.Duration
+	for i, frame := range frames {
+		out := ansi.CursorHomePosition + strings.ReplaceAll(strings.TrimSuffix(frame, "\n"), "\n", "\r\n")
+		if i == 0 {
+			out = ansi.HideCursor + ansi.EraseEntireScreen + out
+		}
+		if err := enc.Encode([]any{at.Seconds(), "o", out}); err != nil {
+			return fmt.Errorf("writing asciicast: %w", err)
+		}
+		at += playbackDelay(g, i, speed)
+	}
+
+	// An empty event holds the last frame for its delay
+	if err := enc.Encode([]any{at.Seconds(), "o", ""}); err != nil {
+		return fmt.Errorf("writing asciicast: %w", err)
+	}
+	return bw.Flush()
+}
+
+// ============================================================================
+// HTML
+// ============================================================================
+
+// htmlStyle is the page stylesheet; frames are stacked on the first one,
+// which sizes the container
+const htmlStyle = `body{margin:1em}
+.jif{position:relative;display:inline-block}
+.jif pre{margin:0;font:16px/1 monospace}
+.jif .f{position:absolute;top:0;left:0}
+.jif .f:first-child{position:relative}
+`
+
+// htmlSpan returns the character and inline style that draw cell
+func htmlSpan(cell halfBlock) (char, style string) {
+	switch {
+	case cell.Top == nil && cell.Bottom == nil:
+		return " ", ""
+	case cell.Top == nil:
+		return "▄", "color:" + hexColor(cell.Bottom)
+	case cell.Bottom == nil:
+		return "▀", "color:" + hexColor(cell.Top)
+	}
+	return "▀", "color:" + hexColor(cell.Top) + ";background:" + hexColor(cell.Bottom)
+}
+
+// writeHTMLFrame writes grid as a pre block, one span per run of cells that
+// look the same
+func writeHTMLFrame(w io.Writer, i int, grid cellGrid) {
+	fmt.Fprintf(w, `<pre class="f" id="f%d">`, i)
+	for _, row := range grid {
+		for x := 0; x < len(row); {
+			char, style := htmlSpan(row[x])
+			run := 1
+			for x+run < len(row) {
+				c, s := htmlSpan(row[x+run])
+				if c != char || s != style {
+					break
+				}
+				run++
+			}
+			x += run
+
+			text := strings.Repeat(char, run)
+			if style == "" {
+				io.WriteString(w, text)
+				continue
+			}
+			fmt.Fprintf(w, `<span style="%s">%s</span>`, style, text)
+		}
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, "</pre>\n")
+}
+
+// writeHTML writes frames as a self-contained page of coloured text, animated
+// with CSS and no scripts
+func writeHTML(w io.Writer, title string, frames []cellGrid, anim animation) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(bw, "<style>\n%s%s</style>\n</head>\n<body>\n<div class=\"jif\">\n", htmlStyle, anim.css())
+	for i, grid := range frames {
+		writeHTMLFrame(bw, i, grid)
+	}
+	io.WriteString(bw, "</div>\n</body>\n</html>\n")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing HTML: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// SVG
+// ============================================================================
+
+// sameColor reports whether a and b draw the same, treating nil as
+// transparent
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return hexColor(a) == hexColor(b)
+}
+
+// writeSVGFrame writes grid as a group of rectangles, one per run of pixels
+// of the same colour in each half row
+func writeSVGFrame(w io.Writer, i int, grid cellGrid, cellWidth, pixelHeight float64) {
+	fmt.Fprintf(w, "<g class=\"f\" id=\"f%d\">\n", i)
+	for y, row := range grid {
+		for half := range 2 {
+			pixel := func(x int) color.Color {
+				if half == 0 {
+					return row[x].Top
+				}
+				return row[x].Bottom
+			}
+
+			for x := 0; x < len(row); {
+				c := pixel(x)
+				run := 1
+				for x+run < len(row) && sameColor(pixel(x+run), c) {
+					run++
+				}
+				if c != nil {
+					fmt.Fprintf(w, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\" fill=\"%s\"/>\n",
+						float64(x)*cellWidth, float64(2*y+half)*pixelHeight,
+						float64(run)*cellWidth, pixelHeight, hexColor(c))
+				}
+				x += run
+			}
+		}
+	}
+	io.WriteString(w, "</g>\n")
+}
+
+// writeSVG writes frames as an animated SVG, drawing each cell as two
+// rectangles sized for cellAspect and animating them with CSS
+func writeSVG(w io.Writer, frames []cellGrid, anim animation, cellAspect float64) error {
+	cellWidth := float64(svgCellWidth)
+	pixelHeight := cellWidth * cellAspect / 2
+
+	rows, cols := len(frames[0]), 0
+	if rows > 0 {
+		cols = len(frames[0][0])
+	}
+	width, height := float64(cols)*cellWidth, float64(rows)*2*pixelHeight
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\" shape-rendering=\"crispEdges\">\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, "<style>\n%s</style>\n", anim.css())
+	for i, grid := range frames {
+		writeSVGFrame(bw, i, grid, cellWidth, pixelHeight)
+	}
+	io.WriteString(bw, "</svg>\n")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("writing SVG: %w", err)
+	}
+	return nil
+}
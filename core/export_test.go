@@ -0,0 +1,134 @@
+package jif
+
+import (
+	"bytes"
+	"flag"
+	"image/color"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestExportGolden(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+		opts   Options
+	}{
+		{format: "asciicast", golden: "../testdata/simple.cast", opts: Options{Width: 20}},
+		{format: "html", golden: "../testdata/simple.html", opts: Options{Width: 20}},
+		{format: "svg", golden: "../testdata/simple.svg", opts: Options{Width: 10, Loop: "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, "../testdata/simple.gif", tt.format, tt.opts); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			if *update {
+				if err := os.WriteFile(tt.golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("Export() output differs from %s (run with -update if the change is intended)", tt.golden)
+			}
+
+			// The same options give the same output
+			var again bytes.Buffer
+			if err := Export(&again, "../testdata/simple.gif", tt.format, tt.opts); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+				t.Errorf("Export() is not deterministic")
+			}
+		})
+	}
+}
+
+func TestExportAsciicastTiming(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Width: 10, Speed: 2, Background: "terminal"}
+	if err := Export(&buf, "../testdata/simple.gif", "cast", opts); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{`{"version":2,"width":10,"height":`, `[0,"o",`, `[0.1,"o",`, `[0.2,"o",""]`}
+	if len(lines) != len(want) {
+		t.Fatalf("Export() wrote %d lines, want %d", len(lines), len(want))
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Export() line %d = %.40q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+	}{
+		{name: "no format", format: "", opts: Options{Speed: 1}},
+		{name: "unknown format", format: "mp4", opts: Options{Speed: 1}},
+		{name: "bad speed", format: "asciicast", opts: Options{Speed: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, "../testdata/simple.gif", tt.format, tt.opts); err == nil {
+				t.Errorf("Export() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestAnimationCSS(t *testing.T) {
+	anim := animation{Delays: []time.Duration{100 * time.Millisecond, 300 * time.Millisecond}, Plays: 2}
+	want := `.f{visibility:hidden;animation:0.4s step-end 2 forwards}
+#f0{animation-name:f0}
+@keyframes f0{0%{visibility:visible}25%{visibility:hidden}100%{visibility:hidden}}
+#f1{animation-name:f1}
+@keyframes f1{0%{visibility:hidden}25%{visibility:visible}100%{visibility:visible}}
+`
+	if got := anim.css(); got != want {
+		t.Errorf("css() = %q, want %q", got, want)
+	}
+}
+
+func TestHTMLSpan(t *testing.T) {
+	red, blue := color.RGBA{R: 0xff, A: 0xff}, color.RGBA{B: 0xff, A: 0xff}
+
+	tests := []struct {
+		name      string
+		cell      halfBlock
+		wantChar  string
+		wantStyle string
+	}{
+		{name: "transparent", cell: halfBlock{}, wantChar: " ", wantStyle: ""},
+		{name: "top only", cell: halfBlock{Top: red}, wantChar: "▀", wantStyle: "color:#ff0000"},
+		{name: "bottom only", cell: halfBlock{Bottom: blue}, wantChar: "▄", wantStyle: "color:#0000ff"},
+		{name: "both", cell: halfBlock{Top: red, Bottom: blue}, wantChar: "▀", wantStyle: "color:#ff0000;background:#0000ff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char, style := htmlSpan(tt.cell)
+			if char != tt.wantChar || style != tt.wantStyle {
+				t.Errorf("htmlSpan() = %q, %q, want %q, %q", char, style, tt.wantChar, tt.wantStyle)
+			}
+		})
+	}
+}
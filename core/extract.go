@@ -0,0 +1,203 @@
+package jif
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// extractSidecar is the name of the JSON file written next to the frames
+const extractSidecar = "frames.json"
+
+// ExtractOptions configures Extract
+type ExtractOptions struct {
+	Range string // 1-based inclusive frames, e.g. 10:20, 10: or 5; empty for all
+	Raw   bool   // write each frame's own rectangle instead of the composited canvas
+}
+
+// extractManifest is the JSON sidecar describing the extracted frames
+type extractManifest struct {
+	Source    string           `json:"source"`
+	Mode      string           `json:"mode"`
+	Width     int              `json:"width"`
+	Height    int              `json:"height"`
+	LoopCount int              `json:"loop_count"`
+	Frames    []extractedFrame `json:"frames"`
+}
+
+// extractedFrame describes one written frame; the offset and size are those
+// of the frame's rectangle in the GIF, whichever mode it was written in.
+// DelayMS is the delay stored in the file and PlaybackMS the one played,
+// which differs for frames stored without a delay.
+type extractedFrame struct {
+	Frame      int    `json:"frame"`
+	File       string `json:"file"`
+	DelayMS    int64  `json:"delay_ms"`
+	PlaybackMS int64  `json:"playback_delay_ms"`
+	Disposal   string `json:"disposal"`
+	X          int    `json:"x"`
+	Y          int    `json:"y"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+}
+
+// ============================================================================
+// Frame Ranges
+// ============================================================================
+
+// parseFrameRange parses a 1-based inclusive range of n frames such as 10:20,
+// 10:, :20 or 5, returning the 0-based half-open range it covers
+func parseFrameRange(s string, n int) (start, end int, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, n, nil
+	}
+
+	first, last, isRange := strings.Cut(s, ":")
+	if !isRange {
+		last = first
+	}
+
+	bound := func(text string, fallback int) (int, error) {
+		if text == "" {
+			return fallback, nil
+		}
+		v, err := strconv.Atoi(text)
+		if err != nil {
+			return 0, fmt.Errorf("invalid frame range %q (want first:last, e.g. 10:20)", s)
+		}
+		return v, nil
+	}
+	if start, err = bound(first, 1); err != nil {
+		return 0, 0, err
+	}
+	if end, err = bound(last, n); err != nil {
+		return 0, 0, err
+	}
+
+	if start < 1 || end > n || start > end {
+		return 0, 0, fmt.Errorf("frame range %q out of range (1-%d)", s, n)
+	}
+	return start - 1, end, nil
+}
+
+// ============================================================================
+// Extraction
+// ============================================================================
+
+// frameFileName names frame i (0-based) of n, padded so the files sort in
+// order
+func frameFileName(i, n int) string {
+	digits := max(3, len(strconv.Itoa(n)))
+	return fmt.Sprintf("frame-%0*d.png", digits, i+1)
+}
+
+// writePNG encodes img to path
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// frameImages calls fn with each frame of g in [start, end), composited
+// onto the canvas or as the frame's own rectangle when raw is set
+func frameImages(g *gif.GIF, start, end int, raw bool, fn func(i int, img image.Image) error) error {
+	if raw {
+		for i := start; i < end; i++ {
+			if err := fn(i, g.Image[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		if err == nil && i >= start && i < end {
+			err = fn(i, img)
+		}
+	})
+	return err
+}
+
+// Extract writes frames of source to dir as PNG files named frame-001.png
+// and so on, along with a frames.json sidecar giving each frame's delay,
+// disposal and offset. It returns the number of frames written.
+func Extract(source, dir string, opts ExtractOptions) (int, error) {
+	g, _, err := loadGIFWithStats(source)
+	if err != nil {
+		return 0, fmt.Errorf("loading GIF: %w", err)
+	}
+	start, end, err := parseFrameRange(opts.Range, len(g.Image))
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+
+	width, height := getGifDimensions(g)
+	manifest := extractManifest{
+		Source:    filepath.Base(source),
+		Mode:      "composited",
+		Width:     width,
+		Height:    height,
+		LoopCount: g.LoopCount,
+	}
+	if opts.Raw {
+		manifest.Mode = "raw"
+	}
+
+	err = frameImages(g, start, end, opts.Raw, func(i int, img image.Image) error {
+		name := frameFileName(i, len(g.Image))
+		if err := writePNG(filepath.Join(dir, name), img); err != nil {
+			return err
+		}
+
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var delay int64
+		if i < len(g.Delay) {
+			delay = int64(g.Delay[i]) * 10
+		}
+		rect := g.Image[i].Rect
+		manifest.Frames = append(manifest.Frames, extractedFrame{
+			Frame:      i + 1,
+			File:       name,
+			DelayMS:    delay,
+			PlaybackMS: frameDelay(g, i).Milliseconds(),
+			Disposal:   disposalName(disposal),
+			X:          rect.Min.X,
+			Y:          rect.Min.Y,
+			Width:      rect.Dx(),
+			Height:     rect.Dy(),
+		})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, extractSidecar), append(data, '\n'), 0o644); err != nil {
+		return 0, err
+	}
+	return len(manifest.Frames), nil
+}
@@ -0,0 +1,160 @@
+package jif
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFrameRange(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{in: "", wantStart: 0, wantEnd: 10},
+		{in: "3:5", wantStart: 2, wantEnd: 5},
+		{in: "4:", wantStart: 3, wantEnd: 10},
+		{in: ":2", wantStart: 0, wantEnd: 2},
+		{in: "7", wantStart: 6, wantEnd: 7},
+		{in: "1:10", wantStart: 0, wantEnd: 10},
+		{in: "0:3", wantErr: true},
+		{in: "5:11", wantErr: true},
+		{in: "6:5", wantErr: true},
+		{in: "a:b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := parseFrameRange(tt.in, 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFrameRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("parseFrameRange(%q) = %d, %d, want %d, %d", tt.in, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestFrameFileName(t *testing.T) {
+	tests := []struct {
+		i, n int
+		want string
+	}{
+		{i: 0, n: 10, want: "frame-001.png"},
+		{i: 41, n: 100, want: "frame-042.png"},
+		{i: 0, n: 1500, want: "frame-0001.png"},
+	}
+
+	for _, tt := range tests {
+		if got := frameFileName(tt.i, tt.n); got != tt.want {
+			t.Errorf("frameFileName(%d, %d) = %q, want %q", tt.i, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	const source = "../testdata/disposal.gif"
+	g, err := loadGIF(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canvasWidth, canvasHeight := getGifDimensions(g)
+
+	tests := []struct {
+		name string
+		opts ExtractOptions
+		want []int
+	}{
+		{name: "composited", opts: ExtractOptions{}, want: []int{1, 2, 3}},
+		{name: "raw range", opts: ExtractOptions{Range: "2:3", Raw: true}, want: []int{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			n, err := Extract(source, dir, tt.opts)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if n != len(tt.want) {
+				t.Fatalf("Extract() = %d, want %d", n, len(tt.want))
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, extractSidecar))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var manifest extractManifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				t.Fatalf("sidecar is not valid JSON: %v", err)
+			}
+
+			for i, frame := range manifest.Frames {
+				if frame.Frame != tt.want[i] {
+					t.Errorf("frame %d = %d, want %d", i, frame.Frame, tt.want[i])
+				}
+
+				f, err := os.Open(filepath.Join(dir, frame.File))
+				if err != nil {
+					t.Fatal(err)
+				}
+				img, err := png.Decode(f)
+				f.Close()
+				if err != nil {
+					t.Fatalf("decoding %s: %v", frame.File, err)
+				}
+
+				wantWidth, wantHeight := canvasWidth, canvasHeight
+				if tt.opts.Raw {
+					wantWidth, wantHeight = frame.Width, frame.Height
+				}
+				if img.Bounds().Dx() != wantWidth || img.Bounds().Dy() != wantHeight {
+					t.Errorf("%s is %v, want %dx%d", frame.File, img.Bounds().Size(), wantWidth, wantHeight)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractDelays(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	source := filepath.Join(t.TempDir(), "delays.gif")
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+		},
+		Delay: []int{0, 25},
+	}
+	if _, err := writeGIF(source, g); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := Extract(source, dir, ExtractOptions{}); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, extractSidecar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest extractManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stored 0 is reported as is, alongside the 100ms it plays for
+	want := [][2]int64{{0, 100}, {250, 250}}
+	for i, frame := range manifest.Frames {
+		if got := [2]int64{frame.DelayMS, frame.PlaybackMS}; got != want[i] {
+			t.Errorf("frame %d delay_ms, playback_delay_ms = %v, want %v", frame.Frame, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package jif
+
+import (
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// Thumbnail geometry in terminal cells, excluding the border
+const (
+	thumbWidth = 16
+	thumbRows  = 4
+)
+
+// filmstripHeight is the number of rows taken by the filmstrip, including
+// the thumbnail borders
+const filmstripHeight = thumbRows + 2
+
+// thumbSlot is the width of one bordered thumbnail
+const thumbSlot = thumbWidth + 2
+
+// ============================================================================
+// Filmstrip
+// ============================================================================
+
+// imageHeight returns the rows available to the main image, leaving room for
+// the filmstrip when it is shown on a tall enough terminal
+func (m model) imageHeight() int {
+	if m.showsFilmstrip() {
+		return m.Height - filmstripHeight
+	}
+	return m.Height
+}
+
+// showsFilmstrip reports whether the filmstrip is enabled and fits
+func (m model) showsFilmstrip() bool {
+	return m.ShowFilmstrip && m.Height > filmstripHeight*2 && m.Width >= thumbSlot
+}
+
+// toggleFilmstrip shows or hides the filmstrip and re-renders the frames for
+// the new image area
+func (m *model) toggleFilmstrip() {
+	m.ShowFilmstrip = !m.ShowFilmstrip
+	m.refreshFilmstrip()
+}
+
+// filmstripWindow returns the range of frames shown as thumbnails, keeping
+// the current frame in the middle where possible
+func (m model) filmstripWindow() (start, end int) {
+	total := len(m.Composited)
+	count := min(total, max(1, m.Width/thumbSlot))
+
+	start = max(0, min(m.CurrentFrame-count/2, total-count))
+	return start, start + count
+}
+
+// refreshFilmstrip renders any visible thumbnails that are not cached yet
+func (m *model) refreshFilmstrip() {
+	if !m.showsFilmstrip() || len(m.Composited) == 0 {
+		return
+	}
+	if m.Thumbnails == nil {
+		m.Thumbnails = make(map[int]string)
+	}
+
+	// Thumbnails use the same halfblock pipeline as the main image, fitted
+	// into a tiny terminal
+	thumb := m.tileModel(thumbWidth, thumbRows)
+
+	start, end := m.filmstripWindow()
+	for i := start; i < end; i++ {
+		if _, ok := m.Thumbnails[i]; !ok {
+			m.Thumbnails[i] = thumb.renderImageHalfBlock(m.Composited[i], nil)
+		}
+	}
+}
+
+// filmstripBounds returns the x offset and top row of the filmstrip
+func (m model) filmstripBounds() (left, top int) {
+	start, end := m.filmstripWindow()
+	return max(0, (m.Width-(end-start)*thumbSlot)/2), m.Height - filmstripHeight
+}
+
+// filmstripFrameAt returns the frame whose thumbnail covers cell x, y
+func (m model) filmstripFrameAt(x, y int) (int, bool) {
+	if !m.showsFilmstrip() || len(m.Composited) == 0 {
+		return 0, false
+	}
+
+	left, top := m.filmstripBounds()
+	if y < top || x < left {
+		return 0, false
+	}
+
+	start, end := m.filmstripWindow()
+	i := start + (x-left)/thumbSlot
+	if i >= end {
+		return 0, false
+	}
+	return i, true
+}
+
+// jumpToFrame pauses playback on frame i
+func (m *model) jumpToFrame(i int) {
+	m.stepFrame(i - m.CurrentFrame)
+}
+
+func (m model) renderFilmstrip() string {
+	start, end := m.filmstripWindow()
+
+	thumbs := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		borderColor := m.colors().Muted
+		if i == m.CurrentFrame {
+			borderColor = m.colors().Accent
+		}
+
+		thumbs = append(thumbs, lipgloss.NewStyle().
+			Width(thumbSlot).
+			Height(filmstripHeight).
+			AlignHorizontal(lipgloss.Center).
+			AlignVertical(lipgloss.Center).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(borderColor).
+			Render(strings.TrimSuffix(m.Thumbnails[i], "\n")))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, thumbs...)
+}
@@ -0,0 +1,127 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+func newFilmstripModel(frames int) *model {
+	composited := make([]*image.RGBA, frames)
+	rendered := make([]string, frames)
+	delays := make([]int, frames)
+	for i := range composited {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+		img.Set(0, 0, color.RGBA{uint8(i), 0, 0, 255})
+		composited[i] = img
+		rendered[i] = "frame"
+		delays[i] = 10
+	}
+
+	return &model{
+		player: player{
+			Frames:     rendered,
+			Composited: composited,
+			GIF:        &gif.GIF{Delay: delays},
+		},
+		Width:         100,
+		Height:        30,
+		Ready:         true,
+		ShowFilmstrip: true,
+	}
+}
+
+func TestFilmstripLayout(t *testing.T) {
+	m := newFilmstripModel(20)
+
+	if got := m.imageHeight(); got != 30-filmstripHeight {
+		t.Errorf("imageHeight() = %d, want %d", got, 30-filmstripHeight)
+	}
+
+	m.ShowFilmstrip = false
+	if got := m.imageHeight(); got != 30 {
+		t.Errorf("imageHeight() without filmstrip = %d, want 30", got)
+	}
+
+	// A short terminal has no room for the strip
+	m.ShowFilmstrip = true
+	m.Height = 10
+	if m.showsFilmstrip() {
+		t.Error("filmstrip should be hidden on a short terminal")
+	}
+}
+
+func TestFilmstripWindow(t *testing.T) {
+	m := newFilmstripModel(20)
+
+	// 100 columns fit 5 thumbnails
+	m.CurrentFrame = 10
+	if start, end := m.filmstripWindow(); start != 8 || end != 13 {
+		t.Errorf("filmstripWindow() = %d..%d, want 8..13", start, end)
+	}
+
+	m.CurrentFrame = 0
+	if start, end := m.filmstripWindow(); start != 0 || end != 5 {
+		t.Errorf("filmstripWindow() at start = %d..%d, want 0..5", start, end)
+	}
+
+	m.CurrentFrame = 19
+	if start, end := m.filmstripWindow(); start != 15 || end != 20 {
+		t.Errorf("filmstripWindow() at end = %d..%d, want 15..20", start, end)
+	}
+}
+
+func TestRefreshFilmstrip(t *testing.T) {
+	m := newFilmstripModel(20)
+	m.refreshFilmstrip()
+
+	if len(m.Thumbnails) != 5 {
+		t.Errorf("refreshFilmstrip() rendered %d thumbnails, want 5", len(m.Thumbnails))
+	}
+
+	m.stepFrame(5)
+	if _, ok := m.Thumbnails[7]; !ok {
+		t.Error("stepping frames should render newly visible thumbnails")
+	}
+
+	strip := m.renderFilmstrip()
+	if w, h := lipgloss.Width(strip), lipgloss.Height(strip); w != 5*thumbSlot || h != filmstripHeight {
+		t.Errorf("renderFilmstrip() is %dx%d, want %dx%d", w, h, 5*thumbSlot, filmstripHeight)
+	}
+}
+
+func TestFilmstripClick(t *testing.T) {
+	m := newFilmstripModel(20)
+	m.refreshFilmstrip()
+
+	// Third thumbnail of frames 0..5, centred in 100 columns
+	left, top := m.filmstripBounds()
+	_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseLeft, X: left + 2*thumbSlot + 1, Y: top + 1})
+
+	if m.CurrentFrame != 2 {
+		t.Errorf("clicking a thumbnail: CurrentFrame = %d, want 2", m.CurrentFrame)
+	}
+	if !m.Paused {
+		t.Error("jumping to a frame should pause playback")
+	}
+	if m.drag.active {
+		t.Error("clicking a thumbnail should not start a drag")
+	}
+}
+
+func TestFilmstripKey(t *testing.T) {
+	m := newFilmstripModel(3)
+	m.ShowFilmstrip = false
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if !m.ShowFilmstrip {
+		t.Error("t should show the filmstrip")
+	}
+	if cmd == nil {
+		t.Error("toggling the filmstrip should re-render frames for the new image area")
+	}
+}
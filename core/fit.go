@@ -0,0 +1,88 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// fitMode controls how the GIF canvas is mapped onto the terminal
+type fitMode int
+
+const (
+	fitContain  fitMode = iota // Scale to fit entirely, preserving aspect ratio
+	fitCover                   // Scale to fill, cropping the overflow
+	fitStretch                 // Fill the terminal, ignoring aspect ratio
+	fitOriginal                // No scaling, centred and pannable when larger
+)
+
+var fitModeNames = []string{"contain", "cover", "stretch", "original"}
+
+func (f fitMode) String() string {
+	if int(f) < len(fitModeNames) {
+		return fitModeNames[f]
+	}
+	return "unknown"
+}
+
+// next returns the fit mode that follows f when cycling
+func (f fitMode) next() fitMode {
+	return (f + 1) % fitMode(len(fitModeNames))
+}
+
+// parseFitMode converts a fit mode name into a fitMode; empty means contain
+func parseFitMode(s string) (fitMode, error) {
+	if s == "" {
+		return fitContain, nil
+	}
+	for i, name := range fitModeNames {
+		if strings.EqualFold(s, name) {
+			return fitMode(i), nil
+		}
+	}
+	return fitContain, fmt.Errorf("invalid fit mode %q (want one of %s)", s, strings.Join(fitModeNames, ", "))
+}
+
+// fitSource returns the part of bounds that is visible for the current fit
+// mode when not zoomed; cover crops the centre to the terminal aspect ratio
+func (m model) fitSource(bounds image.Rectangle) image.Rectangle {
+	if m.Fit != fitCover || bounds.Empty() || m.Width < m.charsPerPixel() || m.imageHeight() < 1 {
+		return bounds
+	}
+
+	// Terminal shape in source pixels, undoing the row stretch
+	termW := float64(m.Width / m.charsPerPixel())
+	termH := float64(m.imageHeight()*2) / m.pixelStretch()
+
+	// Image is wider than the terminal - crop the sides
+	if float64(bounds.Dx())*termH > float64(bounds.Dy())*termW {
+		w := int(float64(bounds.Dy()) * termW / termH)
+		x := bounds.Min.X + (bounds.Dx()-w)/2
+		return image.Rect(x, bounds.Min.Y, x+w, bounds.Max.Y)
+	}
+
+	// Image is taller than the terminal - crop top and bottom
+	h := int(float64(bounds.Dx()) * termH / termW)
+	y := bounds.Min.Y + (bounds.Dy()-h)/2
+	return image.Rect(bounds.Min.X, y, bounds.Max.X, y+h)
+}
+
+// cycleFit switches to the next fit mode and re-centres the viewport
+func (m *model) cycleFit() {
+	m.Fit = m.Fit.next()
+	m.Zoom = 0
+	m.centerViewport()
+}
+
+// subImage crops img to r when the image type supports it
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if r == img.Bounds() {
+		return img
+	}
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	return img
+}
@@ -0,0 +1,156 @@
+package jif
+
+import (
+	"image"
+	"image/gif"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseFitMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    fitMode
+		wantErr bool
+	}{
+		{"", fitContain, false},
+		{"contain", fitContain, false},
+		{"COVER", fitCover, false},
+		{"stretch", fitStretch, false},
+		{"original", fitOriginal, false},
+		{"zoom", fitContain, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFitMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFitMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseFitMode(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateImageSizeFitModes(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+
+	tests := []struct {
+		fit        fitMode
+		wantWidth  int
+		wantHeight int
+	}{
+		{fitContain, 80, 40},
+		{fitCover, 80, 80},
+		{fitStretch, 80, 80},
+		{fitOriginal, 200, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fit.String(), func(t *testing.T) {
+			m := &model{Width: 80, Height: 40, Fit: tt.fit}
+			gotWidth, gotHeight := m.calculateImageSize(img)
+			if gotWidth != tt.wantWidth || gotHeight != tt.wantHeight {
+				t.Errorf("calculateImageSize() = %dx%d, want %dx%d", gotWidth, gotHeight, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestFitSourceCover(t *testing.T) {
+	m := &model{Width: 80, Height: 40, Fit: fitCover}
+
+	// An 80x40 terminal of 1:2 cells is square, so a wide image loses its sides
+	got := m.fitSource(image.Rect(0, 0, 200, 100))
+	if want := image.Rect(50, 0, 150, 100); got != want {
+		t.Errorf("fitSource(wide) = %v, want %v", got, want)
+	}
+
+	// A tall image loses its top and bottom
+	got = m.fitSource(image.Rect(0, 0, 10, 100))
+	if want := image.Rect(0, 45, 10, 55); got != want {
+		t.Errorf("fitSource(tall) = %v, want %v", got, want)
+	}
+
+	m.Fit = fitContain
+	if got := m.fitSource(image.Rect(0, 0, 200, 100)); got != image.Rect(0, 0, 200, 100) {
+		t.Errorf("fitSource() should not crop in contain mode, got %v", got)
+	}
+}
+
+func TestCycleFitKey(t *testing.T) {
+	m := &model{
+		player: player{
+			Frames:     []string{"frame1"},
+			Composited: []*image.RGBA{image.NewRGBA(image.Rect(0, 0, 200, 100))},
+			GIF:        &gif.GIF{Delay: []int{10}},
+		},
+		Width:  40,
+		Height: 10,
+		Ready:  true,
+	}
+
+	var cmd tea.Cmd
+	for _, want := range []fitMode{fitCover, fitStretch, fitOriginal, fitContain} {
+		m.Ready = true
+		_, cmd = m.Update(tea.KeyPressMsg{Code: 'f', Text: "f"})
+		if m.Fit != want {
+			t.Errorf("Fit = %v, want %v", m.Fit, want)
+		}
+		if cmd == nil {
+			t.Error("changing fit mode should re-render frames")
+		}
+	}
+}
+
+func TestOriginalFitPans(t *testing.T) {
+	m := &model{
+		player: player{Frames: []string{"frame1"}, Composited: []*image.RGBA{image.NewRGBA(image.Rect(0, 0, 200, 100))}},
+		Width:  40,
+		Height: 10,
+		Fit:    fitOriginal,
+	}
+	m.centerViewport()
+
+	// 40x10 cells show 40x20 pixels of the 200x100 canvas, centred
+	if m.PanX != 80 || m.PanY != 40 {
+		t.Errorf("original fit should start centred, got (%d,%d)", m.PanX, m.PanY)
+	}
+	if m.ViewportFrame == "" {
+		t.Error("original fit should render the visible viewport")
+	}
+
+	m.handleDirection(1, 0)
+	if m.PanX <= 80 {
+		t.Errorf("arrow keys should pan in original mode, PanX = %d", m.PanX)
+	}
+}
+
+func TestOriginalFitSkipsPrerender(t *testing.T) {
+	g, err := loadGIF("../testdata/multi.gif")
+	if err != nil {
+		t.Fatalf("loadGIF() error = %v", err)
+	}
+
+	m := &model{player: player{GIF: g}, Width: 40, Height: 10, Fit: fitOriginal}
+	m.ProcessGIF(nil)()
+	if len(m.Composited) != len(g.Image) || len(m.Frames) != len(g.Image) {
+		t.Fatalf("ProcessGIF() gave %d canvases and %d frames, want %d", len(m.Composited), len(m.Frames), len(g.Image))
+	}
+	for i, frame := range m.Frames {
+		if frame != "" {
+			t.Errorf("frame %d was pre-rendered at original size", i)
+		}
+	}
+
+	// Only the visible part is drawn, once playback starts
+	m.handleProcessingComplete()
+	if m.currentFrameView() == "" {
+		t.Error("original fit should render the visible viewport")
+	}
+	if got := strings.Count(strings.TrimSuffix(m.currentFrameView(), "\n"), "\n") + 1; got > m.imageHeight() {
+		t.Errorf("viewport is %d rows, want at most %d", got, m.imageHeight())
+	}
+}
@@ -0,0 +1,407 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Grid tile geometry in terminal cells, excluding the border
+const (
+	gridTileWidth = 24
+	gridTileRows  = 10
+)
+
+// gridSlotWidth and gridSlotHeight are the size of one bordered tile,
+// including its file name label
+const (
+	gridSlotWidth  = gridTileWidth + 2
+	gridSlotHeight = gridTileRows + 3
+)
+
+// tileLoadedMsg carries a grid tile rendered in the background
+type tileLoadedMsg struct {
+	index  int
+	frames []string
+	loaded loadedGIF
+	err    error
+}
+
+// gridTickMsg advances one grid tile; gen discards ticks from a previous
+// grid session or from before the tiles on screen changed
+type gridTickMsg struct {
+	index int
+	gen   int
+}
+
+// player holds the playback state of one GIF so that several can animate
+// independently. Composited and RenderTime are only kept for the GIF shown
+// full-screen, where frames are inspected and re-rendered on demand.
+type player struct {
+	Source       string
+	GIF          *gif.GIF
+	Stats        loadStats
+	Frames       []string
+	Composited   []*image.RGBA
+	CurrentFrame int
+	RenderTime   time.Duration
+	Loading      bool
+	Err          error
+}
+
+// advance moves to the next frame, wrapping at the end
+func (p *player) advance() {
+	if len(p.Frames) > 0 {
+		p.CurrentFrame = (p.CurrentFrame + 1) % len(p.Frames)
+	}
+}
+
+// delay returns how long the current frame is shown
+func (p *player) delay() time.Duration {
+	return frameDelay(p.GIF, p.CurrentFrame)
+}
+
+// view returns the rendered current frame
+func (p *player) view() string {
+	if p.CurrentFrame < len(p.Frames) {
+		return p.Frames[p.CurrentFrame]
+	}
+	return ""
+}
+
+// tileModel returns a model that renders into a width x height cell area
+// with the same cell geometry as m
+func (m model) tileModel(width, height int) model {
+	return model{
+		Width:              width,
+		Height:             height,
+		CellAspect:         m.CellAspect,
+		PixelChars:         m.PixelChars,
+		Background:         m.Background,
+		TerminalBackground: m.TerminalBackground,
+	}
+}
+
+// loadPlayerFrames decodes source, unless it is already cached, and renders
+// every composited frame with tile
+func loadPlayerFrames(tile model, source string, cached *loadedGIF) ([]string, loadedGIF, error) {
+	var loaded loadedGIF
+	if cached != nil {
+		loaded = *cached
+	} else {
+		g, stats, err := loadGIFWithStats(source)
+		if err != nil {
+			return nil, loaded, err
+		}
+		loaded = loadedGIF{gif: g, stats: stats}
+	}
+
+	frames := make([]string, len(loaded.gif.Image))
+	compositeFrames(loaded.gif, func(i int, img *image.RGBA) {
+		frames[i] = tile.renderImageHalfBlock(img, nil)
+	})
+	return frames, loaded, nil
+}
+
+// ============================================================================
+// Grid Gallery
+// ============================================================================
+
+// toggleGrid enters or leaves the grid gallery
+func (m *model) toggleGrid() tea.Cmd {
+	if m.ShowGrid {
+		m.ShowGrid = false
+		m.gridGen++
+		return nil
+	}
+	if len(m.Playlist) < 2 {
+		return nil
+	}
+
+	m.ShowGrid = true
+	m.gridGen++
+	if len(m.Grid) != len(m.Playlist) {
+		m.Grid = make([]*player, len(m.Playlist))
+		for i, source := range m.Playlist {
+			m.Grid[i] = &player{Source: source}
+		}
+	}
+	m.GridSelected = m.PlaylistIndex
+	m.scrollGrid()
+
+	// Restart the tiles on screen that were loaded in a previous session
+	m.gridStart, m.gridEnd = -1, -1
+	return m.loadVisibleTiles()
+}
+
+// gridColumns returns how many tiles fit side by side
+func (m model) gridColumns() int {
+	return max(1, m.Width/gridSlotWidth)
+}
+
+// gridRows returns how many rows of tiles fit below the status line
+func (m model) gridRows() int {
+	return max(1, (m.Height-1)/gridSlotHeight)
+}
+
+// scrollGrid keeps the selected tile on screen
+func (m *model) scrollGrid() {
+	row := m.GridSelected / m.gridColumns()
+	if row < m.GridOffset {
+		m.GridOffset = row
+	}
+	if row >= m.GridOffset+m.gridRows() {
+		m.GridOffset = row - m.gridRows() + 1
+	}
+}
+
+// gridWindow returns the range of tiles currently on screen
+func (m model) gridWindow() (start, end int) {
+	start = m.GridOffset * m.gridColumns()
+	end = min(len(m.Grid), start+m.gridRows()*m.gridColumns())
+	return start, end
+}
+
+// moveGridSelection moves the selection by dx columns and dy rows
+func (m *model) moveGridSelection(dx, dy int) tea.Cmd {
+	cols := m.gridColumns()
+	selected := m.GridSelected + dx + dy*cols
+	if selected < 0 || selected >= len(m.Grid) {
+		return nil
+	}
+
+	m.GridSelected = selected
+	m.scrollGrid()
+	return m.loadVisibleTiles()
+}
+
+// loadVisibleTiles starts rendering the on-screen tiles that are not loaded.
+// When the window has moved, only the loaded tiles now on screen animate,
+// and tiles far from it release their frames.
+func (m *model) loadVisibleTiles() tea.Cmd {
+	var cmds []tea.Cmd
+	start, end := m.gridWindow()
+	if start != m.gridStart || end != m.gridEnd {
+		m.gridStart, m.gridEnd = start, end
+		m.gridGen++
+		m.releaseDistantTiles(start, end)
+		for i := start; i < end; i++ {
+			if len(m.Grid[i].Frames) > 0 {
+				cmds = append(cmds, m.gridTick(i))
+			}
+		}
+	}
+
+	for i := start; i < end; i++ {
+		tile := m.Grid[i]
+		if tile.Loading || tile.Frames != nil || tile.Err != nil {
+			continue
+		}
+		tile.Loading = true
+		cmds = append(cmds, m.loadTile(i))
+	}
+	return tea.Batch(cmds...)
+}
+
+// releaseDistantTiles drops the frames of tiles more than a screen away
+// from the window [start, end); they are rendered again if scrolled back
+func (m *model) releaseDistantTiles(start, end int) {
+	margin := end - start
+	for i, tile := range m.Grid {
+		if i >= start-margin && i < end+margin || tile.Loading {
+			continue
+		}
+		tile.GIF, tile.Frames, tile.CurrentFrame = nil, nil, 0
+	}
+}
+
+// tileVisible reports whether tile i is on screen
+func (m model) tileVisible(i int) bool {
+	start, end := m.gridWindow()
+	return i >= start && i < end
+}
+
+// loadTile decodes and renders a tile at grid size in the background,
+// reusing a GIF already decoded for the playlist
+func (m *model) loadTile(i int) tea.Cmd {
+	source := m.Grid[i].Source
+	tile := m.tileModel(gridTileWidth, gridTileRows)
+
+	var cached *loadedGIF
+	if loaded, ok := m.preloaded[source]; ok {
+		cached = &loaded
+	}
+
+	return func() tea.Msg {
+		frames, loaded, err := loadPlayerFrames(tile, source, cached)
+		return tileLoadedMsg{index: i, frames: frames, loaded: loaded, err: err}
+	}
+}
+
+// gridTick schedules the next frame of tile i on its own timing
+func (m model) gridTick(i int) tea.Cmd {
+	gen := m.gridGen
+	return tea.Tick(m.Grid[i].delay(), func(time.Time) tea.Msg {
+		return gridTickMsg{index: i, gen: gen}
+	})
+}
+
+func (m *model) handleTileLoaded(msg tileLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.index >= len(m.Grid) {
+		return m, nil
+	}
+
+	tile := m.Grid[msg.index]
+	tile.Loading = false
+	tile.Err = msg.err
+	tile.GIF = msg.loaded.gif
+	tile.Stats = msg.loaded.stats
+	tile.Frames = msg.frames
+
+	if msg.err != nil || !m.ShowGrid || !m.tileVisible(msg.index) {
+		return m, nil
+	}
+	return m, m.gridTick(msg.index)
+}
+
+func (m *model) handleGridTick(msg gridTickMsg) (tea.Model, tea.Cmd) {
+	if !m.ShowGrid || msg.gen != m.gridGen || !m.tileVisible(msg.index) {
+		return m, nil
+	}
+
+	m.Grid[msg.index].advance()
+	return m, m.gridTick(msg.index)
+}
+
+// openGridSelection leaves the grid and shows the selected file full-screen
+func (m *model) openGridSelection() tea.Cmd {
+	m.ShowGrid = false
+	m.gridGen++
+
+	if m.GridSelected == m.PlaylistIndex {
+		return nil
+	}
+	return m.switchFile(m.GridSelected - m.PlaylistIndex)
+}
+
+func (m *model) handleGridKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keys := m.keyMap()
+
+	switch {
+	case key.Matches(msg, keys.Left):
+		return m, m.moveGridSelection(-1, 0)
+	case key.Matches(msg, keys.Right):
+		return m, m.moveGridSelection(1, 0)
+	case key.Matches(msg, keys.Up):
+		return m, m.moveGridSelection(0, -1)
+	case key.Matches(msg, keys.Down):
+		return m, m.moveGridSelection(0, 1)
+	case key.Matches(msg, keys.Open):
+		return m, m.openGridSelection()
+	case key.Matches(msg, keys.Grid, keys.Back):
+		return m, m.toggleGrid()
+	case key.Matches(msg, keys.Quit):
+		return m, m.quitOrBrowse()
+	case key.Matches(msg, keys.ForceQuit):
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// gridTileAt returns the tile covering cell x, y
+func (m model) gridTileAt(x, y int) (int, bool) {
+	if y < 1 {
+		return 0, false
+	}
+
+	col, row := x/gridSlotWidth, (y-1)/gridSlotHeight
+	if col >= m.gridColumns() || row >= m.gridRows() {
+		return 0, false
+	}
+
+	i := (m.GridOffset+row)*m.gridColumns() + col
+	return i, i < len(m.Grid)
+}
+
+func (m *model) handleGridClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
+	i, ok := m.gridTileAt(msg.X, msg.Y)
+	if !ok || msg.Button != tea.MouseLeft {
+		return m, nil
+	}
+
+	// Clicking the selected tile opens it
+	if i == m.GridSelected {
+		return m, m.openGridSelection()
+	}
+	m.GridSelected = i
+	return m, nil
+}
+
+// renderGridTile draws one tile with its border and file name
+func (m model) renderGridTile(i int) string {
+	tile := m.Grid[i]
+
+	var content string
+	switch {
+	case tile.Err != nil:
+		content = "error"
+	case tile.Frames == nil:
+		content = "loading..."
+	default:
+		content = strings.TrimSuffix(tile.view(), "\n")
+	}
+
+	image := lipgloss.NewStyle().
+		Width(gridTileWidth).
+		Height(gridTileRows).
+		AlignHorizontal(lipgloss.Center).
+		AlignVertical(lipgloss.Center).
+		Render(content)
+
+	label := ansi.Truncate(filepath.Base(tile.Source), gridTileWidth, "…")
+
+	borderColor := m.colors().Muted
+	if i == m.GridSelected {
+		borderColor = m.colors().Accent
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Render(lipgloss.JoinVertical(lipgloss.Center, image, label))
+}
+
+func (m model) renderGrid() *lipgloss.Layer {
+	start, end := m.gridWindow()
+	cols := m.gridColumns()
+
+	var rows []string
+	for rowStart := start; rowStart < end; rowStart += cols {
+		var tiles []string
+		for i := rowStart; i < min(end, rowStart+cols); i++ {
+			tiles = append(tiles, m.renderGridTile(i))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, tiles...))
+	}
+
+	keys := m.keyMap()
+	status := fmt.Sprintf(" Grid %d/%d  %s ", m.GridSelected+1, len(m.Grid), keyHints(
+		keyHint(keys.Open, "open"),
+		keyHint(keys.Grid, "back"),
+		keyHint(keys.Quit, "quit"),
+	))
+	layers := []*lipgloss.Layer{
+		lipgloss.NewLayer(lipgloss.JoinVertical(lipgloss.Left, rows...)).X(0).Y(1).Z(0),
+		lipgloss.NewLayer(m.colors().label().Render(status)).X(1).Y(0).Z(5),
+	}
+
+	return lipgloss.NewLayer(lipgloss.NewCanvas(layers...).Render())
+}
@@ -0,0 +1,215 @@
+package jif
+
+import (
+	"image/gif"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newGridModel(files int) *model {
+	playlist := make([]string, files)
+	for i := range playlist {
+		playlist[i] = "../testdata/simple.gif"
+	}
+	// Room for 3 columns and 2 rows of tiles below the status line
+	return &model{Width: gridSlotWidth*3 + 5, Height: gridSlotHeight*2 + 1, Playlist: playlist, PixelChars: 1}
+}
+
+func TestToggleGrid(t *testing.T) {
+	m := newGridModel(8)
+	m.PlaylistIndex = 7
+
+	if m.toggleGrid() == nil {
+		t.Fatal("toggleGrid() returned no command to load tiles")
+	}
+	if !m.ShowGrid || len(m.Grid) != 8 {
+		t.Fatalf("ShowGrid = %v, len(Grid) = %d, want true, 8", m.ShowGrid, len(m.Grid))
+	}
+	if m.GridSelected != 7 || m.GridOffset != 1 {
+		t.Errorf("GridSelected, GridOffset = %d, %d, want 7, 1", m.GridSelected, m.GridOffset)
+	}
+
+	// Only the tiles on screen are loaded
+	for i, tile := range m.Grid {
+		if want := i >= 3; tile.Loading != want {
+			t.Errorf("Grid[%d].Loading = %v, want %v", i, tile.Loading, want)
+		}
+	}
+
+	m.toggleGrid()
+	if m.ShowGrid {
+		t.Error("toggleGrid() did not close the grid")
+	}
+
+	single := newGridModel(1)
+	single.toggleGrid()
+	if single.ShowGrid {
+		t.Error("toggleGrid() opened the grid for a single file")
+	}
+}
+
+func TestMoveGridSelection(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      int
+		dx, dy     int
+		wantSel    int
+		wantOffset int
+	}{
+		{"right", 0, 1, 0, 1, 0},
+		{"left at start stays", 0, -1, 0, 0, 0},
+		{"down", 1, 0, 1, 4, 0},
+		{"down scrolls", 4, 0, 1, 7, 1},
+		{"down past end stays", 7, 0, 1, 7, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newGridModel(8)
+			m.toggleGrid()
+			m.GridSelected, m.GridOffset = tt.start, 0
+
+			m.moveGridSelection(tt.dx, tt.dy)
+			if m.GridSelected != tt.wantSel || m.GridOffset != tt.wantOffset {
+				t.Errorf("GridSelected, GridOffset = %d, %d, want %d, %d",
+					m.GridSelected, m.GridOffset, tt.wantSel, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestGridTileAt(t *testing.T) {
+	m := newGridModel(5)
+	m.toggleGrid()
+
+	tests := []struct {
+		x, y   int
+		want   int
+		wantOK bool
+	}{
+		{0, 0, 0, false},
+		{0, 1, 0, true},
+		{gridSlotWidth + 1, 2, 1, true},
+		{gridSlotWidth, 1 + gridSlotHeight, 4, true},
+		{gridSlotWidth * 2, 1 + gridSlotHeight, 0, false},
+		{gridSlotWidth * 3, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := m.gridTileAt(tt.x, tt.y)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("gridTileAt(%d, %d) = %d, %v, want %d, %v", tt.x, tt.y, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestGridTileLoadAndTick(t *testing.T) {
+	m := newGridModel(2)
+	m.toggleGrid()
+
+	msg := m.loadTile(1)()
+	loaded, ok := msg.(tileLoadedMsg)
+	if !ok || loaded.err != nil {
+		t.Fatalf("loadTile() = %#v, want tileLoadedMsg", msg)
+	}
+	if len(loaded.frames) != len(loaded.loaded.gif.Image) {
+		t.Fatalf("loadTile() rendered %d frames, want %d", len(loaded.frames), len(loaded.loaded.gif.Image))
+	}
+
+	if _, cmd := m.Update(loaded); cmd == nil {
+		t.Error("Update(tileLoadedMsg) did not schedule a tick")
+	}
+
+	tile := m.Grid[1]
+	m.Update(gridTickMsg{index: 1, gen: m.gridGen})
+	if tile.CurrentFrame != 1%len(tile.Frames) {
+		t.Errorf("CurrentFrame = %d after tick, want %d", tile.CurrentFrame, 1%len(tile.Frames))
+	}
+
+	// Ticks from an earlier grid session are dropped
+	m.Update(gridTickMsg{index: 1, gen: m.gridGen - 1})
+	if tile.CurrentFrame != 1%len(tile.Frames) {
+		t.Errorf("stale tick advanced tile to frame %d", tile.CurrentFrame)
+	}
+}
+
+func TestGridKeys(t *testing.T) {
+	m := newGridModel(3)
+	m.toggleGrid()
+
+	m.Update(tea.KeyPressMsg{Code: 'l', Text: "l"})
+	if m.GridSelected != 1 {
+		t.Errorf("GridSelected = %d after l, want 1", m.GridSelected)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.ShowGrid {
+		t.Error("esc did not close the grid")
+	}
+}
+
+func TestOpenGridSelectionWhileLoading(t *testing.T) {
+	m := newGridModel(3)
+	m.GIF = &gif.GIF{Delay: []int{10}}
+	m.Frames = []string{"frame1"}
+	m.toggleGrid()
+
+	// The first file is still rendering when another tile is opened
+	m.Loading = true
+	m.Update(tea.KeyPressMsg{Code: 'l', Text: "l"})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.ShowGrid || m.PlaylistIndex != 0 {
+		t.Fatalf("ShowGrid, PlaylistIndex = %v, %d, want false, 0 until rendering ends", m.ShowGrid, m.PlaylistIndex)
+	}
+
+	_, cmd := m.Update(processingCompleteMsg{})
+	if m.PlaylistIndex != 1 || cmd == nil {
+		t.Errorf("PlaylistIndex = %d after rendering, want the opened tile 1", m.PlaylistIndex)
+	}
+	if m.fileQueued {
+		t.Error("the queued file should only be shown once")
+	}
+}
+
+func TestGridOffscreenTiles(t *testing.T) {
+	m := newGridModel(30)
+	m.toggleGrid()
+	for _, tile := range m.Grid {
+		tile.Loading = false
+		tile.GIF = &gif.GIF{Delay: []int{10, 10}}
+		tile.Frames = []string{"a", "b"}
+	}
+
+	// Scroll down four rows, so tiles 9 to 14 are on screen
+	for range 4 {
+		m.moveGridSelection(0, 1)
+	}
+	if start, end := m.gridWindow(); start != 9 || end != 15 {
+		t.Fatalf("gridWindow() = %d, %d, want 9, 15", start, end)
+	}
+
+	// Tiles that scrolled away stop ticking, those on screen keep going
+	if _, cmd := m.Update(gridTickMsg{index: 4, gen: m.gridGen}); cmd != nil || m.Grid[4].CurrentFrame != 0 {
+		t.Error("a tile scrolled off screen kept ticking")
+	}
+	if _, cmd := m.Update(gridTickMsg{index: 10, gen: m.gridGen}); cmd == nil || m.Grid[10].CurrentFrame != 1 {
+		t.Error("a tile on screen stopped ticking")
+	}
+
+	// Tiles more than a screen away release their frames
+	if m.Grid[0].Frames != nil || m.Grid[29].Frames != nil || m.Grid[3].Frames == nil || m.Grid[14].Frames == nil {
+		t.Error("only tiles more than a screen from the window should release their frames")
+	}
+
+	// Scrolling back renders released tiles again and restarts loaded ones
+	for range 4 {
+		m.moveGridSelection(0, -1)
+	}
+	if !m.Grid[0].Loading {
+		t.Error("a released tile was not rendered again on coming back into view")
+	}
+	if _, cmd := m.Update(gridTickMsg{index: 4, gen: m.gridGen}); cmd == nil {
+		t.Error("a tile back on screen did not animate")
+	}
+}
@@ -0,0 +1,268 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+	"time"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// loadStats records how the GIF was read, for the info panel
+type loadStats struct {
+	FileSize   int64
+	DecodeTime time.Duration
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ============================================================================
+// GIF Metadata
+// ============================================================================
+
+var disposalNames = map[byte]string{
+	0:                      "unspecified",
+	gif.DisposalNone:       "none",
+	gif.DisposalBackground: "background",
+	gif.DisposalPrevious:   "previous",
+}
+
+// disposalName describes a GIF disposal method
+func disposalName(d byte) string {
+	if name, ok := disposalNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", d)
+}
+
+// frameDelay returns how long frame i is shown during playback
+func frameDelay(g *gif.GIF, i int) time.Duration {
+	delay := 10 // Default to 100ms if no delay specified
+	if i < len(g.Delay) && g.Delay[i] > 0 {
+		delay = g.Delay[i]
+	}
+	return time.Duration(delay) * 10 * time.Millisecond
+}
+
+// totalDuration sums the playback delay of all frames
+func totalDuration(g *gif.GIF) time.Duration {
+	var total time.Duration
+	for i := range g.Image {
+		total += frameDelay(g, i)
+	}
+	return total
+}
+
+// loopDescription explains gif.GIF.LoopCount
+func loopDescription(loopCount int) string {
+	switch {
+	case loopCount == 0:
+		return "forever"
+	case loopCount < 0:
+		return "once"
+	case loopCount == 1:
+		return "2 times"
+	default:
+		return fmt.Sprintf("%d times", loopCount+1)
+	}
+}
+
+// globalPalette returns the GIF's global colour table, if any
+func globalPalette(g *gif.GIF) color.Palette {
+	p, _ := g.Config.ColorModel.(color.Palette)
+	return p
+}
+
+// transparentIndex returns the palette index decoded as fully transparent, or
+// -1; image/gif zeroes that entry when a frame has a transparency index
+func transparentIndex(p color.Palette) int {
+	for i, c := range p {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasLocalPalette reports whether a frame carries its own colour table rather
+// than the global one, ignoring the entry cleared for transparency
+func hasLocalPalette(frame *image.Paletted, global color.Palette) bool {
+	if len(frame.Palette) != len(global) {
+		return true
+	}
+	for i, c := range frame.Palette {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			continue
+		}
+		r1, g1, b1, a1 := c.RGBA()
+		r2, g2, b2, a2 := global[i].RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// hexColor formats a colour as #rrggbb
+func hexColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+}
+
+// ============================================================================
+// Info Panel
+// ============================================================================
+
+// infoSummary lists the GIF-wide metadata as label/value pairs
+func (m model) infoSummary() [][2]string {
+	g := m.GIF
+	global := globalPalette(g)
+
+	localCount := 0
+	for _, frame := range g.Image {
+		if hasLocalPalette(frame, global) {
+			localCount++
+		}
+	}
+
+	palette := "none"
+	if global != nil {
+		palette = fmt.Sprintf("global %d colours", len(global))
+	}
+	if localCount > 0 {
+		palette += fmt.Sprintf(", %d local", localCount)
+	}
+
+	background := "n/a"
+	if int(g.BackgroundIndex) < len(global) {
+		background = fmt.Sprintf("%s (index %d)", hexColor(global[g.BackgroundIndex]), g.BackgroundIndex)
+	}
+
+	width, height := getGifDimensions(g)
+	rows := [][2]string{
+		{"Screen", fmt.Sprintf("%dx%d", g.Config.Width, g.Config.Height)},
+		{"Canvas", fmt.Sprintf("%dx%d", width, height)},
+		{"Frames", fmt.Sprintf("%d", len(g.Image))},
+		{"Duration", totalDuration(g).String()},
+		{"Loop", loopDescription(g.LoopCount)},
+		{"Palette", palette},
+		{"Background", background},
+	}
+
+	if m.Stats.FileSize > 0 {
+		rows = append(rows, [2]string{"File size", formatBytes(m.Stats.FileSize)})
+	}
+	if m.Stats.DecodeTime > 0 {
+		rows = append(rows, [2]string{"Decode", m.Stats.DecodeTime.Round(time.Microsecond).String()})
+	}
+	if m.RenderTime > 0 {
+		rows = append(rows, [2]string{"Render", m.RenderTime.Round(time.Microsecond).String()})
+	}
+
+	return rows
+}
+
+// infoFrameRow describes frame i for the per-frame table
+func (m model) infoFrameRow(i int) string {
+	g := m.GIF
+	frame := g.Image[i]
+
+	var disposal byte
+	if i < len(g.Disposal) {
+		disposal = g.Disposal[i]
+	}
+
+	palette := "global"
+	if hasLocalPalette(frame, globalPalette(g)) {
+		palette = fmt.Sprintf("local %d", len(frame.Palette))
+	}
+
+	transparent := "-"
+	if ti := transparentIndex(frame.Palette); ti >= 0 {
+		transparent = fmt.Sprintf("%d", ti)
+	}
+
+	marker := " "
+	if i == m.CurrentFrame {
+		marker = "▶"
+	}
+
+	return fmt.Sprintf("%s %4d %7s %-11s %-9s %5s %v",
+		marker, i+1, frameDelay(g, i), disposalName(disposal), palette, transparent, frame.Rect)
+}
+
+// infoFrameWindow returns the range of frames to list so that the current
+// frame stays visible within maxRows
+func (m model) infoFrameWindow(maxRows int) (start, end int) {
+	total := len(m.GIF.Image)
+	if maxRows <= 0 {
+		return 0, 0
+	}
+	if total <= maxRows {
+		return 0, total
+	}
+
+	start = max(0, min(m.CurrentFrame-maxRows/2, total-maxRows))
+	return start, start + maxRows
+}
+
+func (m model) renderInfo() string {
+	t := m.colors()
+	title := t.title().Render("GIF Info")
+
+	label := t.label()
+
+	var sb strings.Builder
+	summary := m.infoSummary()
+	for _, row := range summary {
+		sb.WriteString(label.Render(fmt.Sprintf("%-11s", row[0])))
+		sb.WriteString(row[1])
+		sb.WriteString("\n")
+	}
+
+	header := label.Render(fmt.Sprintf("  %4s %7s %-11s %-9s %5s %s",
+		"#", "Delay", "Disposal", "Palette", "Trans", "Rect"))
+
+	// Leave room for the border, padding, title, summary and table header
+	maxRows := m.Height - len(summary) - 8
+	start, end := m.infoFrameWindow(maxRows)
+
+	frames := make([]string, 0, end-start+1)
+	frames = append(frames, header)
+	for i := start; i < end; i++ {
+		frames = append(frames, m.infoFrameRow(i))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title, "", strings.TrimRight(sb.String(), "\n"), "", strings.Join(frames, "\n"))
+
+	return t.panel().Render(content)
+}
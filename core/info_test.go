@@ -0,0 +1,138 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestLoopDescription(t *testing.T) {
+	tests := []struct {
+		loopCount int
+		want      string
+	}{
+		{0, "forever"},
+		{-1, "once"},
+		{1, "2 times"},
+		{4, "5 times"},
+	}
+
+	for _, tt := range tests {
+		if got := loopDescription(tt.loopCount); got != tt.want {
+			t.Errorf("loopDescription(%d) = %q, want %q", tt.loopCount, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KiB"},
+		{3 * 1024 * 1024, "3.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPaletteDetection(t *testing.T) {
+	global := color.Palette{
+		color.RGBA{0, 0, 0, 255},
+		color.RGBA{255, 0, 0, 255},
+	}
+
+	// Same colours with the transparency entry cleared, as image/gif decodes it
+	shared := image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.RGBA{}, global[1]})
+	if hasLocalPalette(shared, global) {
+		t.Error("a global palette with a transparent entry should not count as local")
+	}
+	if got := transparentIndex(shared.Palette); got != 0 {
+		t.Errorf("transparentIndex() = %d, want 0", got)
+	}
+
+	local := image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{global[0], color.RGBA{0, 0, 255, 255}})
+	if !hasLocalPalette(local, global) {
+		t.Error("a palette with different colours should count as local")
+	}
+	if got := transparentIndex(local.Palette); got != -1 {
+		t.Errorf("transparentIndex() = %d, want -1", got)
+	}
+}
+
+func TestTotalDuration(t *testing.T) {
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, 3),
+		Delay: []int{5, 0, 20},
+	}
+
+	// A zero delay plays at the 100ms default
+	if got, want := totalDuration(g), 350*time.Millisecond; got != want {
+		t.Errorf("totalDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestInfoFrameWindow(t *testing.T) {
+	m := model{player: player{GIF: &gif.GIF{Image: make([]*image.Paletted, 100)}, CurrentFrame: 50}}
+
+	start, end := m.infoFrameWindow(10)
+	if start != 45 || end != 55 {
+		t.Errorf("infoFrameWindow(10) = %d..%d, want 45..55", start, end)
+	}
+
+	m.CurrentFrame = 98
+	start, end = m.infoFrameWindow(10)
+	if start != 90 || end != 100 {
+		t.Errorf("infoFrameWindow(10) at end = %d..%d, want 90..100", start, end)
+	}
+}
+
+func TestRenderInfo(t *testing.T) {
+	g, stats, err := loadGIFWithStats("../testdata/disposal.gif")
+	if err != nil {
+		t.Fatalf("loadGIFWithStats() error = %v", err)
+	}
+
+	info, err := os.Stat("../testdata/disposal.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.FileSize != info.Size() {
+		t.Errorf("FileSize = %d, want %d", stats.FileSize, info.Size())
+	}
+
+	m := &model{player: player{GIF: g, Stats: stats}, Width: 120, Height: 50}
+	panel := ansi.Strip(m.renderInfo())
+
+	for _, want := range []string{"GIF Info", "Screen", "Frames", "Loop", "Palette", "File size", "Disposal", "background"} {
+		if !strings.Contains(panel, want) {
+			t.Errorf("info panel missing %q:\n%s", want, panel)
+		}
+	}
+}
+
+func TestInfoKeyToggles(t *testing.T) {
+	m := &model{}
+
+	_, _ = m.Update(tea.KeyPressMsg{Code: 'i', Text: "i"})
+	if !m.ShowInfo {
+		t.Error("i should show the info panel")
+	}
+
+	_, _ = m.Update(tea.KeyPressMsg{Code: 'i', Text: "i"})
+	if m.ShowInfo {
+		t.Error("i should hide the info panel again")
+	}
+}
@@ -0,0 +1,58 @@
+package jif
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// defaultInlineHeight is the height of inline playback in terminal rows
+const defaultInlineHeight = 20
+
+// ============================================================================
+// Inline Playback
+// ============================================================================
+
+// parseInline validates the inline playback settings
+func parseInline(opts Options) error {
+	if !opts.Inline {
+		return nil
+	}
+	if opts.Height < 0 {
+		return fmt.Errorf("invalid height %d (want a number of rows)", opts.Height)
+	}
+	if opts.ExitFrame < 0 {
+		return fmt.Errorf("invalid exit frame %d (want a frame number, or 0 for the frame on screen)", opts.ExitFrame)
+	}
+	if opts.Grid {
+		return fmt.Errorf("--inline plays a single file and cannot be used with --grid")
+	}
+	return nil
+}
+
+// quit ends the program; inline playback first settles on the frame that
+// stays in the scrollback
+func (m *model) quit() tea.Cmd {
+	if m.Inline {
+		m.quitting = true
+		if m.ExitFrame > 0 && len(m.Frames) > 0 {
+			m.CurrentFrame = min(m.ExitFrame, len(m.Frames)) - 1
+			m.refreshViewport()
+		}
+	}
+	return tea.Quit
+}
+
+// renderExitFrame draws the frame left printed after inline playback, without
+// the status bar or padding rows
+func (m model) renderExitFrame() *lipgloss.Layer {
+	if !m.Ready || len(m.Frames) == 0 {
+		return lipgloss.NewLayer("")
+	}
+	return lipgloss.NewLayer(lipgloss.NewStyle().
+		Width(m.Width).
+		AlignHorizontal(lipgloss.Center).
+		Render(strings.TrimSuffix(m.currentFrameView(), "\n")))
+}
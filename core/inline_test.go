@@ -0,0 +1,105 @@
+package jif
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseInline(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"not inline", Options{Height: -1}, false},
+		{"inline", Options{Inline: true, Height: 12, ExitFrame: 3}, false},
+		{"negative height", Options{Inline: true, Height: -1}, true},
+		{"negative exit frame", Options{Inline: true, ExitFrame: -1}, true},
+		{"with grid", Options{Inline: true, Grid: true}, true},
+	}
+
+	for _, tt := range tests {
+		if err := parseInline(tt.opts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseInline() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInlineView(t *testing.T) {
+	m := newZoomModel()
+	m.Inline = true
+	m.InlineHeight = 6
+	m.Width, m.Height = 0, 0
+	m.handleWindowResize(tea.WindowSizeMsg{Width: 40, Height: 30})
+
+	if m.Height != 6 {
+		t.Errorf("Height = %d, want the inline height 6", m.Height)
+	}
+	if v := m.View(); v.AltScreen || v.MouseMode != tea.MouseModeNone {
+		t.Errorf("inline View() uses the alt screen or mouse: %v, %v", v.AltScreen, v.MouseMode)
+	}
+
+	// Quitting leaves the chosen frame without the status bar
+	m.ExitFrame = 2
+	m.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	if !m.quitting || m.CurrentFrame != 1 {
+		t.Fatalf("after quitting: quitting = %v, frame %d, want frame 1", m.quitting, m.CurrentFrame)
+	}
+	out := ansi.Strip(lipgloss.NewCanvas(m.renderExitFrame()).Render())
+	if strings.TrimSpace(out) != "frame2" {
+		t.Errorf("exit frame = %q, want frame2 alone", out)
+	}
+}
+
+func TestInlineQuitsAfterLastPlay(t *testing.T) {
+	m := newZoomModel()
+	m.Inline = true
+	m.Paused = false
+	m.Loop = "1"
+	m.CurrentFrame = 1
+
+	_, cmd := m.handleFrameAdvance()
+	if cmd == nil || !m.quitting {
+		t.Fatal("inline playback did not quit after its last play")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("command = %T, want tea.QuitMsg", cmd())
+	}
+}
+
+func TestInlineHonoursGIFLoopCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		loopCount int
+		wantQuit  bool
+	}{
+		{"plays once", -1, true},
+		{"plays twice", 1, false},
+		{"loops forever plays once", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newZoomModel()
+			m.Inline = true
+			m.Paused = false
+			m.Loop = LoopFinite
+			m.GIF.LoopCount = tt.loopCount
+			m.CurrentFrame = 1
+
+			_, cmd := m.handleFrameAdvance()
+			if m.quitting != tt.wantQuit {
+				t.Fatalf("quitting = %v after the first play, want %v", m.quitting, tt.wantQuit)
+			}
+			if tt.wantQuit {
+				if _, ok := cmd().(tea.QuitMsg); !ok {
+					t.Errorf("command = %T, want tea.QuitMsg", cmd())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// inspectorStep is how far the shifted movement keys move the cursor
+const inspectorStep = 10
+
+// pixelReport describes one pixel of the current frame
+type pixelReport struct {
+	Pos   image.Point
+	Color color.NRGBA
+
+	// Index is the palette index in the raw frame, or -1 when the frame's
+	// sub-rectangle does not cover the pixel
+	Index int
+	Local bool
+
+	// IndexTransparent is set when Index is the frame's transparency index,
+	// so the pixel shows whatever was composited before
+	IndexTransparent bool
+}
+
+// Transparent reports whether the composited pixel is fully transparent
+func (r pixelReport) Transparent() bool {
+	return r.Color.A == 0
+}
+
+// ============================================================================
+// Pixel Inspector
+// ============================================================================
+
+// toggleInspector enters or leaves inspector mode, starting the cursor in the
+// middle of what is on screen
+func (m *model) toggleInspector() {
+	if m.Inspecting {
+		m.Inspecting = false
+		return
+	}
+	if len(m.Composited) == 0 {
+		return
+	}
+
+	src, _, _ := m.viewport()
+	m.Inspecting = true
+	m.Cursor = image.Pt((src.Min.X+src.Max.X)/2, (src.Min.Y+src.Max.Y)/2)
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor on the canvas
+func (m *model) clampCursor() {
+	if len(m.Composited) == 0 {
+		return
+	}
+
+	canvas := m.Composited[0].Bounds()
+	m.Cursor.X = max(canvas.Min.X, min(m.Cursor.X, canvas.Max.X-1))
+	m.Cursor.Y = max(canvas.Min.Y, min(m.Cursor.Y, canvas.Max.Y-1))
+}
+
+// moveCursor moves the inspector cursor by dx, dy source pixels, panning
+// when zoomed so that it stays on screen
+func (m *model) moveCursor(dx, dy int) {
+	m.Cursor = m.Cursor.Add(image.Pt(dx, dy))
+	m.clampCursor()
+
+	if m.scale() == 0 {
+		return
+	}
+
+	src, _, _ := m.viewport()
+	var panX, panY int
+	switch {
+	case m.Cursor.X < src.Min.X:
+		panX = m.Cursor.X - src.Min.X
+	case m.Cursor.X >= src.Max.X:
+		panX = m.Cursor.X - src.Max.X + 1
+	}
+	switch {
+	case m.Cursor.Y < src.Min.Y:
+		panY = m.Cursor.Y - src.Min.Y
+	case m.Cursor.Y >= src.Max.Y:
+		panY = m.Cursor.Y - src.Max.Y + 1
+	}
+	if panX != 0 || panY != 0 {
+		m.pan(panX, panY)
+	}
+}
+
+// handleInspectorKey moves the cursor with the direction keys, or by
+// inspectorStep with Shift held, reporting whether the key was used
+func (m *model) handleInspectorKey(msg tea.KeyMsg) bool {
+	keys := m.keyMap()
+	moves := []struct {
+		binding key.Binding
+		dx, dy  int
+	}{
+		{keys.Left, -1, 0},
+		{keys.Right, 1, 0},
+		{keys.Up, 0, -1},
+		{keys.Down, 0, 1},
+	}
+
+	if key.Matches(msg, keys.Back) {
+		m.Inspecting = false
+		return true
+	}
+	for _, move := range moves {
+		switch {
+		case key.Matches(msg, move.binding):
+			m.moveCursor(move.dx, move.dy)
+		case key.Matches(msg, shifted(move.binding)):
+			m.moveCursor(move.dx*inspectorStep, move.dy*inspectorStep)
+		default:
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// inspectPixel reports the pixel at pt of the current frame
+func (m model) inspectPixel(pt image.Point) (pixelReport, bool) {
+	if m.CurrentFrame >= len(m.Composited) || !pt.In(m.Composited[m.CurrentFrame].Bounds()) {
+		return pixelReport{}, false
+	}
+
+	report := pixelReport{
+		Pos:   pt,
+		Color: color.NRGBAModel.Convert(m.Composited[m.CurrentFrame].At(pt.X, pt.Y)).(color.NRGBA),
+		Index: -1,
+	}
+
+	if m.GIF == nil || m.CurrentFrame >= len(m.GIF.Image) {
+		return report, true
+	}
+
+	frame := m.GIF.Image[m.CurrentFrame]
+	if pt.In(frame.Rect) {
+		report.Index = int(frame.ColorIndexAt(pt.X, pt.Y))
+		report.Local = hasLocalPalette(frame, globalPalette(m.GIF))
+		report.IndexTransparent = report.Index == transparentIndex(frame.Palette)
+	}
+	return report, true
+}
+
+func (m model) renderInspector() string {
+	t := m.colors()
+	title := t.title().Render("Pixel Inspector")
+
+	label := t.label()
+
+	report, ok := m.inspectPixel(m.Cursor)
+	if !ok {
+		return ""
+	}
+
+	c := report.Color
+	swatch := lipgloss.NewStyle().
+		Background(lipgloss.Color(hexColor(c))).
+		Render("    ")
+
+	index := "not in frame rect"
+	if report.Index >= 0 {
+		palette := "global"
+		if report.Local {
+			palette = "local"
+		}
+		index = fmt.Sprintf("%d (%s)", report.Index, palette)
+		if report.IndexTransparent {
+			index += " transparent"
+		}
+	}
+
+	transparent := "no"
+	switch {
+	case report.Transparent():
+		transparent = "yes"
+	case c.A != 0xff:
+		transparent = fmt.Sprintf("partly (α%d)", c.A)
+	}
+
+	rows := [][2]string{
+		{"Position", fmt.Sprintf("(%d, %d)", report.Pos.X, report.Pos.Y)},
+		{"RGBA", fmt.Sprintf("%d, %d, %d, %d", c.R, c.G, c.B, c.A)},
+		{"Hex", hexColor(c) + " " + swatch},
+		{"Index", index},
+		{"Transparent", transparent},
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.WriteString(label.Render(fmt.Sprintf("%-12s", row[0])))
+		sb.WriteString(row[1])
+		sb.WriteString("\n")
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", strings.TrimRight(sb.String(), "\n"))
+
+	return t.panel().Render(content)
+}
+
+// renderCursor draws the inspector cursor over the half of the cell showing
+// the inspected pixel
+func (m model) renderCursor() (string, image.Point, bool) {
+	cell, bottom, ok := m.sourceToCell(m.Cursor)
+	if !ok {
+		return "", image.Point{}, false
+	}
+
+	block := "▀"
+	if bottom {
+		block = "▄"
+	}
+	cursor := m.colors().highlight().
+		Blink(true).
+		Render(strings.Repeat(block, m.charsPerPixel()))
+	return cursor, cell, true
+}
@@ -0,0 +1,158 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestInspectPixel(t *testing.T) {
+	palette := color.Palette{
+		color.RGBA{0, 0, 0, 0},
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 0, 255, 255},
+	}
+
+	// The second frame only covers the left half, with one transparent pixel
+	first := image.NewPaletted(image.Rect(0, 0, 4, 2), palette)
+	for i := range first.Pix {
+		first.Pix[i] = 2
+	}
+	second := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+	second.Pix = []uint8{1, 0, 1, 1}
+
+	g := &gif.GIF{
+		Image:    []*image.Paletted{first, second},
+		Delay:    []int{10, 10},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: 4, Height: 2},
+	}
+
+	m := &model{player: player{GIF: g, CurrentFrame: 1}}
+	compositeFrames(g, func(_ int, img *image.RGBA) {
+		m.Composited = append(m.Composited, img)
+	})
+
+	tests := []struct {
+		name    string
+		pt      image.Point
+		want    pixelReport
+		wantOK  bool
+		wantHex string
+	}{
+		{
+			name:    "opaque pixel in frame",
+			pt:      image.Pt(0, 0),
+			want:    pixelReport{Pos: image.Pt(0, 0), Index: 1},
+			wantOK:  true,
+			wantHex: "#ff0000",
+		},
+		{
+			name:    "transparent index shows previous frame",
+			pt:      image.Pt(1, 0),
+			want:    pixelReport{Pos: image.Pt(1, 0), Index: 0, IndexTransparent: true},
+			wantOK:  true,
+			wantHex: "#0000ff",
+		},
+		{
+			name:    "outside frame rect",
+			pt:      image.Pt(3, 1),
+			want:    pixelReport{Pos: image.Pt(3, 1), Index: -1},
+			wantOK:  true,
+			wantHex: "#0000ff",
+		},
+		{
+			name: "outside canvas",
+			pt:   image.Pt(4, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.inspectPixel(tt.pt)
+			if ok != tt.wantOK {
+				t.Fatalf("inspectPixel(%v) ok = %v, want %v", tt.pt, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Pos != tt.want.Pos || got.Index != tt.want.Index || got.IndexTransparent != tt.want.IndexTransparent {
+				t.Errorf("inspectPixel(%v) = %+v, want %+v", tt.pt, got, tt.want)
+			}
+			if hex := hexColor(got.Color); hex != tt.wantHex {
+				t.Errorf("inspectPixel(%v) colour = %s, want %s", tt.pt, hex, tt.wantHex)
+			}
+			if got.Transparent() {
+				t.Errorf("inspectPixel(%v) reported a transparent composite", tt.pt)
+			}
+		})
+	}
+}
+
+func TestSourceToCell(t *testing.T) {
+	m := newZoomModel()
+
+	for _, zoom := range []int{0, 1, 4} {
+		m.setZoom(zoom)
+		src, _, _ := m.viewport()
+
+		// Every visible pixel maps onto the image; unless downscaled, that
+		// cell draws the same pixel
+		for y := src.Min.Y; y < src.Max.Y; y++ {
+			for x := src.Min.X; x < src.Max.X; x++ {
+				pt := image.Pt(x, y)
+				cell, bottom, ok := m.sourceToCell(pt)
+				if !ok {
+					t.Fatalf("zoom %d: sourceToCell(%v) not on screen", zoom, pt)
+				}
+				if !cell.In(m.imageBounds()) {
+					t.Fatalf("zoom %d: sourceToCell(%v) = %v outside %v", zoom, pt, cell, m.imageBounds())
+				}
+				if bottom || zoom == 0 {
+					continue
+				}
+				if got, ok := m.cellToSource(cell.X, cell.Y); !ok || got != pt {
+					t.Fatalf("zoom %d: cellToSource(sourceToCell(%v) = %v) = %v, %v", zoom, pt, cell, got, ok)
+				}
+			}
+		}
+	}
+}
+
+func TestInspectorCursor(t *testing.T) {
+	m := newZoomModel()
+
+	m.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	if !m.Inspecting {
+		t.Fatal("c did not start the inspector")
+	}
+	if m.Cursor != image.Pt(100, 50) {
+		t.Errorf("Cursor = %v, want centre (100,50)", m.Cursor)
+	}
+
+	// Arrow keys move the cursor instead of stepping frames
+	m.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	if m.Cursor != image.Pt(101, 50) || m.CurrentFrame != 0 {
+		t.Errorf("after right: Cursor = %v, frame %d, want (101,50), 0", m.Cursor, m.CurrentFrame)
+	}
+
+	m.moveCursor(-500, 500)
+	if m.Cursor != image.Pt(0, 99) {
+		t.Errorf("Cursor = %v, want clamped to (0,99)", m.Cursor)
+	}
+
+	// When zoomed the viewport follows the cursor
+	m.setZoom(4)
+	m.moveCursor(150, -99)
+	if src, _, _ := m.viewport(); !m.Cursor.In(src) {
+		t.Errorf("Cursor %v outside viewport %v", m.Cursor, src)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.Inspecting {
+		t.Error("esc did not leave the inspector")
+	}
+}
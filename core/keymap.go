@@ -0,0 +1,368 @@
+package jif
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"charm.land/bubbles/v2/key"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// keyMap holds every rebindable action; the help overlay is generated from it
+type keyMap struct {
+	// Playback
+	Pause     key.Binding
+	NextFrame key.Binding
+	PrevFrame key.Binding
+
+	// View
+	Left       key.Binding
+	Right      key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	ZoomIn     key.Binding
+	ZoomOut    key.Binding
+	ResetZoom  key.Binding
+	Fit        key.Binding
+	Background key.Binding
+	Filmstrip  key.Binding
+
+	// Tools
+	Info      key.Binding
+	Palette   key.Binding
+	Inspector key.Binding
+	Overlay   key.Binding
+	SaveAs    key.Binding
+
+	// Files
+	NextFile key.Binding
+	PrevFile key.Binding
+	Grid     key.Binding
+	Open     key.Binding
+	Back     key.Binding
+	Filter   key.Binding
+	Sort     key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+
+	// General
+	Help      key.Binding
+	Quit      key.Binding
+	ForceQuit key.Binding
+}
+
+// keyPresets lists the built-in keymaps
+var keyPresets = []string{"default", "vim", "media"}
+
+// namedBinding pairs a binding with the name used for it in config files
+type namedBinding struct {
+	Name    string
+	Binding *key.Binding
+}
+
+// keySection is a titled group of bindings in the help overlay
+type keySection struct {
+	Title    string
+	Bindings []namedBinding
+}
+
+// ============================================================================
+// Presets
+// ============================================================================
+
+// defaultKeyMap returns the standard bindings
+func defaultKeyMap() *keyMap {
+	return &keyMap{
+		Pause:     key.NewBinding(key.WithKeys("space"), key.WithHelp("", "Pause/Resume")),
+		NextFrame: key.NewBinding(key.WithKeys("n"), key.WithHelp("", "Next frame")),
+		PrevFrame: key.NewBinding(key.WithKeys("p"), key.WithHelp("", "Previous frame")),
+
+		Left:       key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("", "Pan left / previous frame")),
+		Right:      key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("", "Pan right / next frame")),
+		Up:         key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("", "Pan up")),
+		Down:       key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("", "Pan down")),
+		ZoomIn:     key.NewBinding(key.WithKeys("+", "="), key.WithHelp("", "Zoom in")),
+		ZoomOut:    key.NewBinding(key.WithKeys("-"), key.WithHelp("", "Zoom out")),
+		ResetZoom:  key.NewBinding(key.WithKeys("0"), key.WithHelp("", "Reset zoom")),
+		Fit:        key.NewBinding(key.WithKeys("f"), key.WithHelp("", "Cycle fit mode")),
+		Background: key.NewBinding(key.WithKeys("b"), key.WithHelp("", "Cycle transparency background")),
+		Filmstrip:  key.NewBinding(key.WithKeys("t"), key.WithHelp("", "Toggle filmstrip")),
+
+		Info:      key.NewBinding(key.WithKeys("i"), key.WithHelp("", "Toggle GIF info")),
+		Palette:   key.NewBinding(key.WithKeys("P"), key.WithHelp("", "Toggle palette & histogram")),
+		Inspector: key.NewBinding(key.WithKeys("c"), key.WithHelp("", "Pixel inspector")),
+		Overlay:   key.NewBinding(key.WithKeys("d"), key.WithHelp("", "Cycle diff / sub-rect overlay")),
+		SaveAs:    key.NewBinding(key.WithKeys("S"), key.WithHelp("", "Save as, with edits")),
+
+		NextFile: key.NewBinding(key.WithKeys("]"), key.WithHelp("", "Next file")),
+		PrevFile: key.NewBinding(key.WithKeys("["), key.WithHelp("", "Previous file")),
+		Grid:     key.NewBinding(key.WithKeys("g"), key.WithHelp("", "Toggle grid gallery")),
+		Open:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("", "Open selection")),
+		Back:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("", "Back / leave mode")),
+		Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("", "Filter files")),
+		Sort:     key.NewBinding(key.WithKeys("s"), key.WithHelp("", "Cycle file sort")),
+		Top:      key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("", "First file")),
+		Bottom:   key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("", "Last file")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("", "Page up")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("", "Page down")),
+
+		Help:      key.NewBinding(key.WithKeys("?"), key.WithHelp("", "Toggle help")),
+		Quit:      key.NewBinding(key.WithKeys("q"), key.WithHelp("", "Quit or back to browser")),
+		ForceQuit: key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("", "Quit")),
+	}
+}
+
+// newKeyMap returns the named preset
+func newKeyMap(preset string) (*keyMap, error) {
+	k := defaultKeyMap()
+
+	switch strings.ToLower(preset) {
+	case "", "default":
+
+	case "vim":
+		// hjkl pan when zoomed, where h and l otherwise step frames like the
+		// arrows; w and b step frames and files move like buffers
+		k.NextFrame.SetKeys("w", "n")
+		k.PrevFrame.SetKeys("b", "N")
+		k.Background.SetKeys("B")
+		k.Left.SetKeys("h", "left")
+		k.Right.SetKeys("l", "right")
+		k.ZoomIn.SetKeys("ctrl+a", "+")
+		k.ZoomOut.SetKeys("ctrl+x", "-")
+		k.NextFile.SetKeys("ctrl+n", "]")
+		k.PrevFile.SetKeys("ctrl+p", "[")
+		k.PageUp.SetKeys("ctrl+u", "pgup")
+		k.PageDown.SetKeys("ctrl+d", "pgdown")
+		k.Quit.SetKeys("q", "Z")
+
+	case "media":
+		// Video player conventions: k pauses, comma and period step frames
+		k.Pause.SetKeys("space", "k")
+		k.NextFrame.SetKeys(".")
+		k.PrevFrame.SetKeys(",")
+		k.Left.SetKeys("left")
+		k.Right.SetKeys("right")
+		k.Up.SetKeys("up")
+		k.Down.SetKeys("down")
+		k.NextFile.SetKeys("N", "]")
+		k.PrevFile.SetKeys("B", "[")
+		k.Top.SetKeys("home")
+		k.Bottom.SetKeys("end")
+		k.Quit.SetKeys("q", "esc")
+		k.Back.SetKeys("backspace")
+
+	default:
+		return nil, fmt.Errorf("unknown keymap %q (want %s)", preset, strings.Join(keyPresets, ", "))
+	}
+
+	return k, nil
+}
+
+// sections groups the bindings under the names used in config files
+func (k *keyMap) sections() []keySection {
+	return []keySection{
+		{"Playback", []namedBinding{
+			{"pause", &k.Pause},
+			{"next_frame", &k.NextFrame},
+			{"prev_frame", &k.PrevFrame},
+		}},
+		{"View", []namedBinding{
+			{"left", &k.Left},
+			{"right", &k.Right},
+			{"up", &k.Up},
+			{"down", &k.Down},
+			{"zoom_in", &k.ZoomIn},
+			{"zoom_out", &k.ZoomOut},
+			{"reset_zoom", &k.ResetZoom},
+			{"fit", &k.Fit},
+			{"background", &k.Background},
+			{"filmstrip", &k.Filmstrip},
+		}},
+		{"Tools", []namedBinding{
+			{"info", &k.Info},
+			{"palette", &k.Palette},
+			{"inspector", &k.Inspector},
+			{"overlay", &k.Overlay},
+			{"save_as", &k.SaveAs},
+		}},
+		{"Files", []namedBinding{
+			{"next_file", &k.NextFile},
+			{"prev_file", &k.PrevFile},
+			{"grid", &k.Grid},
+			{"open", &k.Open},
+			{"back", &k.Back},
+			{"filter", &k.Filter},
+			{"sort", &k.Sort},
+			{"top", &k.Top},
+			{"bottom", &k.Bottom},
+			{"page_up", &k.PageUp},
+			{"page_down", &k.PageDown},
+		}},
+		{"General", []namedBinding{
+			{"help", &k.Help},
+			{"quit", &k.Quit},
+			{"force_quit", &k.ForceQuit},
+		}},
+	}
+}
+
+// override rebinds actions by config name; an empty key list unbinds one
+func (k *keyMap) override(bindings map[string][]string) error {
+	byName := make(map[string]*key.Binding)
+	for _, section := range k.sections() {
+		for _, b := range section.Bindings {
+			byName[b.Name] = b.Binding
+		}
+	}
+
+	// Sorted so that the reported error does not depend on map order
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		b, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+		if keys := bindings[name]; len(keys) > 0 {
+			b.SetKeys(keys...)
+			b.SetEnabled(true)
+		} else {
+			b.SetEnabled(false)
+		}
+	}
+	return nil
+}
+
+// loadKeyMap builds the keymap from a preset and per-action overrides
+func loadKeyMap(preset string, bindings map[string][]string) (*keyMap, error) {
+	k, err := newKeyMap(preset)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.override(bindings); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// keyMap returns the bindings in use
+func (m model) keyMap() *keyMap {
+	if m.keys != nil {
+		return m.keys
+	}
+	return defaultKeys
+}
+
+// defaultKeys backs models created without a keymap
+var defaultKeys = defaultKeyMap()
+
+// shifted returns the bindings' keys with Shift held, for larger steps
+func shifted(b key.Binding) key.Binding {
+	var keys []string
+	for _, k := range b.Keys() {
+		r := []rune(k)
+		switch {
+		case len(r) == 1 && unicode.IsLower(r[0]):
+			keys = append(keys, string(unicode.ToUpper(r[0])))
+		case len(r) > 1 && !strings.Contains(k, "+"):
+			keys = append(keys, "shift+"+k)
+		}
+	}
+	return key.NewBinding(key.WithKeys(keys...))
+}
+
+// ============================================================================
+// Key Help
+// ============================================================================
+
+var keyNames = map[string]string{
+	"space":  "Space",
+	"enter":  "Enter",
+	"esc":    "Esc",
+	"left":   "←",
+	"right":  "→",
+	"up":     "↑",
+	"down":   "↓",
+	"pgup":   "PgUp",
+	"pgdown": "PgDn",
+	"home":   "Home",
+	"end":    "End",
+}
+
+// keyName formats a key for the help overlay, e.g. ctrl+c as Ctrl+C
+func keyName(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+
+	parts := strings.Split(k, "+")
+	if len(k) == 1 || len(parts) == 1 {
+		return k
+	}
+	for i, part := range parts {
+		if name, ok := keyNames[part]; ok {
+			parts[i] = name
+		} else if i < len(parts)-1 || len(part) == 1 {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// bindingKeys formats the keys of a binding for the help overlay
+func bindingKeys(b key.Binding) string {
+	names := make([]string, len(b.Keys()))
+	for i, k := range b.Keys() {
+		names[i] = keyName(k)
+	}
+	return strings.Join(names, " / ")
+}
+
+// helpRows lists the enabled bindings of a section as key/description pairs
+func (s keySection) helpRows() [][2]string {
+	var rows [][2]string
+	for _, b := range s.Bindings {
+		if b.Binding.Enabled() {
+			rows = append(rows, [2]string{bindingKeys(*b.Binding), b.Binding.Help().Desc})
+		}
+	}
+	return rows
+}
+
+// renderHelpSection draws a titled list of keys and what they do
+func (t theme) renderHelpSection(title string, rows [][2]string) string {
+	heading := t.label().Bold(true)
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, lipgloss.Width(row[0]))
+	}
+
+	lines := []string{heading.Render(title)}
+	for _, row := range rows {
+		lines = append(lines, fmt.Sprintf("  %s%s  %s",
+			row[0], strings.Repeat(" ", width-lipgloss.Width(row[0])), row[1]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// keyHint describes one binding for a status line, e.g. "enter open"
+func keyHint(b key.Binding, action string) string {
+	if !b.Enabled() || len(b.Keys()) == 0 {
+		return ""
+	}
+	return keyName(b.Keys()[0]) + " " + action
+}
+
+// keyHints joins the hints of several bindings
+func keyHints(hints ...string) string {
+	return strings.Join(slices.DeleteFunc(hints, func(h string) bool { return h == "" }), "  ")
+}
@@ -0,0 +1,108 @@
+package jif
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		preset  string
+		msg     tea.KeyPressMsg
+		binding func(*keyMap) key.Binding
+		want    bool
+	}{
+		{"default", tea.KeyPressMsg{Code: 'n', Text: "n"}, func(k *keyMap) key.Binding { return k.NextFrame }, true},
+		{"", tea.KeyPressMsg{Code: tea.KeySpace}, func(k *keyMap) key.Binding { return k.Pause }, true},
+		{"vim", tea.KeyPressMsg{Code: 'w', Text: "w"}, func(k *keyMap) key.Binding { return k.NextFrame }, true},
+		{"vim", tea.KeyPressMsg{Code: 'p', Text: "p"}, func(k *keyMap) key.Binding { return k.PrevFrame }, false},
+		{"VIM", tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl}, func(k *keyMap) key.Binding { return k.NextFile }, true},
+		{"media", tea.KeyPressMsg{Code: 'k', Text: "k"}, func(k *keyMap) key.Binding { return k.Pause }, true},
+		{"media", tea.KeyPressMsg{Code: 'k', Text: "k"}, func(k *keyMap) key.Binding { return k.Up }, false},
+		{"media", tea.KeyPressMsg{Code: '.', Text: "."}, func(k *keyMap) key.Binding { return k.NextFrame }, true},
+	}
+
+	for _, tt := range tests {
+		k, err := newKeyMap(tt.preset)
+		if err != nil {
+			t.Fatalf("newKeyMap(%q) error = %v", tt.preset, err)
+		}
+		if got := key.Matches(tt.msg, tt.binding(k)); got != tt.want {
+			t.Errorf("newKeyMap(%q): %q matches = %v, want %v", tt.preset, tt.msg.String(), got, tt.want)
+		}
+	}
+
+	if _, err := newKeyMap("emacs"); err == nil {
+		t.Error("newKeyMap(\"emacs\") expected an error")
+	}
+}
+
+func TestLoadKeyMap(t *testing.T) {
+	k, err := loadKeyMap("vim", map[string][]string{
+		"pause":   {"x", "space"},
+		"overlay": {},
+	})
+	if err != nil {
+		t.Fatalf("loadKeyMap() error = %v", err)
+	}
+
+	if !key.Matches(tea.KeyPressMsg{Code: 'x', Text: "x"}, k.Pause) {
+		t.Error("x does not pause after rebinding")
+	}
+	if k.Overlay.Enabled() {
+		t.Error("overlay still enabled after binding it to nothing")
+	}
+	if !key.Matches(tea.KeyPressMsg{Code: 'w', Text: "w"}, k.NextFrame) {
+		t.Error("rebinding one action lost the preset's other keys")
+	}
+
+	// The help is generated from the keymap, so it follows the overrides
+	var help strings.Builder
+	for _, section := range k.sections() {
+		help.WriteString(defaultTheme.renderHelpSection(section.Title, section.helpRows()))
+	}
+	if !strings.Contains(help.String(), "x / Space") {
+		t.Error("help does not show the rebound pause keys")
+	}
+	if strings.Contains(help.String(), k.Overlay.Help().Desc) {
+		t.Error("help still lists the disabled overlay action")
+	}
+
+	if _, err := loadKeyMap("default", map[string][]string{"jump": {"J"}}); err == nil {
+		t.Error("loadKeyMap() with an unknown action expected an error")
+	}
+}
+
+func TestKeyName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"space", "Space"},
+		{"left", "←"},
+		{"q", "q"},
+		{"+", "+"},
+		{"ctrl+c", "Ctrl+C"},
+		{"shift+left", "Shift+←"},
+		{"ctrl+pgdown", "Ctrl+PgDn"},
+	}
+
+	for _, tt := range tests {
+		if got := keyName(tt.key); got != tt.want {
+			t.Errorf("keyName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestShifted(t *testing.T) {
+	b := key.NewBinding(key.WithKeys("left", "h", "ctrl+b", "+"))
+	got := shifted(b).Keys()
+	want := []string{"shift+left", "H"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("shifted(%v) = %v, want %v", b.Keys(), got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package jif
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+)
+
+// ============================================================================
+// Optimisation
+// ============================================================================
+
+// opaqueFrame copies img to an NRGBA canvas at the origin with every pixel
+// either fully opaque or fully transparent, as it will be once encoded
+func opaqueFrame(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+	for i := 0; i < len(out.Pix); i += 4 {
+		if out.Pix[i+3] < opaqueThreshold {
+			copy(out.Pix[i:i+4], []byte{0, 0, 0, 0})
+		} else {
+			out.Pix[i+3] = 0xff
+		}
+	}
+	return out
+}
+
+// reduceColors maps every frame onto one shared palette of at most n
+// colours, so that areas which do not change keep the same colour. A slot is
+// kept for transparency, which every cropped frame may need.
+func reduceColors(frames []*image.NRGBA, n int) {
+	imgs := make([]image.Image, len(frames))
+	for i, f := range frames {
+		imgs[i] = f
+	}
+	counts, _ := histogram(imgs...)
+	palette := buildPalette(counts, true, n)
+	for _, f := range frames {
+		draw.Draw(f, f.Bounds(), remap(f, palette), f.Bounds().Min, draw.Src)
+	}
+}
+
+// changedRect returns the bounding box of the pixels that differ between a
+// and b, which share bounds
+func changedRect(a, b *image.NRGBA) image.Rectangle {
+	var rect image.Rectangle
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := a.PixOffset(bounds.Min.X, y)
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := row + (x-bounds.Min.X)*4
+			if !bytes.Equal(a.Pix[i:i+4], b.Pix[i:i+4]) {
+				rect = rect.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return rect
+}
+
+// clearedRect returns the bounding box of the pixels that are opaque in a
+// and transparent in b, which only a disposal can clear
+func clearedRect(a, b *image.NRGBA) image.Rectangle {
+	var rect image.Rectangle
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := a.PixOffset(x, y)
+			if a.Pix[i+3] != 0 && b.Pix[i+3] == 0 {
+				rect = rect.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return rect
+}
+
+// cloneNRGBA returns a copy of img
+func cloneNRGBA(img *image.NRGBA) *image.NRGBA {
+	out := image.NewNRGBA(img.Bounds())
+	copy(out.Pix, img.Pix)
+	return out
+}
+
+// deltaFrame returns the part of target inside rect, with the pixels that
+// already show base left transparent
+func deltaFrame(target, base *image.NRGBA, rect image.Rectangle) *image.NRGBA {
+	out := image.NewNRGBA(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := target.PixOffset(x, y)
+			if !bytes.Equal(target.Pix[i:i+4], base.Pix[i:i+4]) {
+				copy(out.Pix[out.PixOffset(x, y):], target.Pix[i:i+4])
+			}
+		}
+	}
+	return out
+}
+
+// optimizeFrames encodes full-canvas frames so that each stores only what
+// changes. Identical consecutive frames are merged by adding their delays,
+// and every frame after the first is cropped to the area that differs from
+// what is already on screen, with unchanged pixels left transparent. Each
+// frame's disposal is the one that leaves the next frame smallest. colors
+// limits the palette shared by all frames; 0 keeps every colour that fits.
+func optimizeFrames(out *gif.GIF, frames []image.Image, delays []int, colors int) {
+	targets := make([]*image.NRGBA, 0, len(frames))
+	var merged []int
+	for i, img := range frames {
+		t := opaqueFrame(img)
+		if n := len(targets); n > 0 && bytes.Equal(targets[n-1].Pix, t.Pix) {
+			merged[n-1] += delays[i]
+			continue
+		}
+		targets = append(targets, t)
+		merged = append(merged, delays[i])
+	}
+	if colors > 0 {
+		reduceColors(targets, colors)
+	}
+
+	bounds := targets[0].Bounds()
+	out.Config = image.Config{Width: bounds.Dx(), Height: bounds.Dy()}
+
+	// The screen before and after the previous frame was drawn
+	var prevRect image.Rectangle
+	before := image.NewNRGBA(bounds)
+	screen := image.NewNRGBA(bounds)
+
+	for i, target := range targets {
+		// The first frame covers the canvas. Later ones draw over whichever
+		// screen the previous frame's disposal leaves that changes least:
+		// none keeps it, background clears its rectangle and previous
+		// restores the screen from before it.
+		base, rect := screen, bounds
+		if i > 0 {
+			cleared := cloneNRGBA(screen)
+			draw.Draw(cleared, prevRect, image.Transparent, image.Point{}, draw.Src)
+
+			candidates := []struct {
+				disposal byte
+				base     *image.NRGBA
+			}{
+				{gif.DisposalNone, screen},
+				{gif.DisposalBackground, cleared},
+				{gif.DisposalPrevious, before},
+			}
+			best := -1
+			for k, c := range candidates {
+				// Transparent pixels cannot cover opaque ones
+				if !clearedRect(c.base, target).Empty() {
+					continue
+				}
+				r := changedRect(c.base, target)
+				if best < 0 || area(r) < area(rect) {
+					best, rect = k, r
+				}
+			}
+			base = candidates[best].base
+			out.Disposal[i-1] = candidates[best].disposal
+		}
+
+		// Keep the pixels the next frame makes transparent inside this
+		// frame, so that clearing it to the background reaches them
+		if i+1 < len(targets) {
+			rect = rect.Union(clearedRect(target, targets[i+1]))
+		}
+		if rect.Empty() {
+			rect = image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+1, bounds.Min.Y+1)
+		}
+
+		frame := quantize(deltaFrame(target, base, rect), maxPaletteSize)
+		out.Image = append(out.Image, frame)
+		out.Delay = append(out.Delay, merged[i])
+		out.Disposal = append(out.Disposal, gif.DisposalNone)
+
+		// Track what is really drawn, which differs from the target when a
+		// frame still had too many colours
+		before = base
+		screen = cloneNRGBA(base)
+		draw.Draw(screen, rect, frame, rect.Min, draw.Over)
+		prevRect = rect
+	}
+}
+
+// area returns the number of pixels in r
+func area(r image.Rectangle) int {
+	return r.Dx() * r.Dy()
+}
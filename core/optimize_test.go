@@ -0,0 +1,173 @@
+package jif
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"testing"
+)
+
+// newOptimizeTestFrames returns 32x32 frames of a red square moving over a
+// grey background, including a repeat, a frame with a transparent hole and
+// a frame that returns to the one before the hole
+func newOptimizeTestFrames() ([]image.Image, []int) {
+	grey := color.RGBA{R: 90, G: 90, B: 90, A: 255}
+	red := color.RGBA{R: 220, A: 255}
+
+	frame := func(square image.Point, hole bool) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+		draw.Draw(img, img.Bounds(), image.NewUniform(grey), image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(0, 0, 4, 4).Add(square), image.NewUniform(red), image.Point{}, draw.Src)
+		if hole {
+			draw.Draw(img, image.Rect(20, 20, 26, 26), image.Transparent, image.Point{}, draw.Src)
+		}
+		return img
+	}
+
+	frames := []image.Image{
+		frame(image.Pt(2, 2), false),
+		frame(image.Pt(4, 2), false),
+		frame(image.Pt(4, 2), false),
+		frame(image.Pt(6, 3), true),
+		frame(image.Pt(6, 3), false),
+	}
+	return frames, []int{5, 5, 7, 5, 5}
+}
+
+func TestOptimizeFrames(t *testing.T) {
+	frames, delays := newOptimizeTestFrames()
+	out := &gif.GIF{}
+	optimizeFrames(out, frames, delays, 0)
+
+	// The repeated third frame is merged into the second
+	want := []image.Image{frames[0], frames[1], frames[3], frames[4]}
+	if len(out.Image) != len(want) {
+		t.Fatalf("optimizeFrames() wrote %d frames, want %d", len(out.Image), len(want))
+	}
+	if got, wantDelay := out.Delay[1], 12; got != wantDelay {
+		t.Errorf("merged delay = %d, want %d", got, wantDelay)
+	}
+	if len(out.Disposal) != len(out.Image) {
+		t.Errorf("got %d disposals for %d frames", len(out.Disposal), len(out.Image))
+	}
+
+	if got := out.Image[0].Bounds(); got != image.Rect(0, 0, 32, 32) {
+		t.Errorf("first frame bounds = %v, want the whole canvas", got)
+	}
+	// Only the moved square is stored, along with the area the next frame
+	// makes transparent so that disposing of this frame can clear it
+	if got, wantRect := out.Image[1].Bounds(), image.Rect(2, 2, 26, 26); got != wantRect {
+		t.Errorf("second frame bounds = %v, want %v", got, wantRect)
+	}
+	if got, wantRect := out.Image[3].Bounds(), image.Rect(20, 20, 26, 26); got != wantRect {
+		t.Errorf("last frame bounds = %v, want %v", got, wantRect)
+	}
+	for i, img := range out.Image[1:] {
+		if img.Bounds().Dx()*img.Bounds().Dy() >= 32*32 {
+			t.Errorf("frame %d was not cropped: %v", i+1, img.Bounds())
+		}
+	}
+
+	// Decoding gives back every frame exactly
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, out); err != nil {
+		t.Fatalf("encoding: %v", err)
+	}
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	compositeFrames(decoded, func(i int, img *image.RGBA) {
+		b := want[i].Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				got := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				exp := color.NRGBAModel.Convert(want[i].At(x, y)).(color.NRGBA)
+				if got.A == 0 && exp.A == 0 {
+					continue
+				}
+				if got != exp {
+					t.Fatalf("frame %d pixel (%d, %d) = %v, want %v", i, x, y, got, exp)
+				}
+			}
+		}
+	})
+}
+
+func TestOptimizeFramesColors(t *testing.T) {
+	var frames []image.Image
+	for i := range 3 {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 4))
+		for x := range 64 {
+			for y := range 4 {
+				img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(i * 40), B: uint8(y * 60), A: 255})
+			}
+		}
+		frames = append(frames, img)
+	}
+
+	out := &gif.GIF{}
+	optimizeFrames(out, frames, []int{1, 1, 1}, 8)
+
+	// Every frame draws from one palette of 8 entries, one of them kept
+	// for transparency
+	colors := make(map[color.Color]bool)
+	for _, img := range out.Image {
+		for _, c := range img.Palette {
+			colors[c] = true
+		}
+	}
+	if len(colors) > 8 {
+		t.Errorf("frames use %d colours, want at most 8", len(colors))
+	}
+}
+
+func TestEditStatsString(t *testing.T) {
+	tests := []struct {
+		stats EditStats
+		want  string
+	}{
+		{EditStats{Frames: 3, Before: 4096, After: 1024}, "3 frames, 4.0 KiB -> 1.0 KiB (75% smaller)"},
+		{EditStats{Frames: 1, Before: 1000, After: 1500}, "1 frames, 1000 B -> 1.5 KiB (50% larger)"},
+		{EditStats{Frames: 2, Before: 100, After: 100}, "2 frames, 100 B -> 100 B"},
+		{EditStats{Frames: 2, After: 100}, "2 frames, 100 B"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stats.String(); got != tt.want {
+			t.Errorf("EditStats.String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestEditOptimizeShrinks(t *testing.T) {
+	g, err := loadGIF("../testdata/multi.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := editGIF(g, EditOptions{})
+	if err != nil {
+		t.Fatalf("editGIF() error = %v", err)
+	}
+	optimized, err := editGIF(g, EditOptions{Optimize: true})
+	if err != nil {
+		t.Fatalf("editGIF(Optimize) error = %v", err)
+	}
+
+	size := func(g *gif.GIF) int {
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, g); err != nil {
+			t.Fatalf("encoding: %v", err)
+		}
+		return buf.Len()
+	}
+	if size(optimized) >= size(plain) {
+		t.Errorf("optimised GIF is %d bytes, plain %d", size(optimized), size(plain))
+	}
+	if _, err := editGIF(g, EditOptions{Colors: 1}); err == nil {
+		t.Errorf("editGIF(Colors: 1) error = nil, want an error")
+	}
+}
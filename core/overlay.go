@@ -0,0 +1,226 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// overlayMode is a debugging view drawn over the current frame
+type overlayMode int
+
+const (
+	overlayNone overlayMode = iota
+	overlayDiff
+	overlayRect
+)
+
+var overlayNames = []string{"none", "diff", "rect"}
+
+func (o overlayMode) String() string {
+	return overlayNames[o]
+}
+
+// next returns the overlay that follows o, wrapping around
+func (o overlayMode) next() overlayMode {
+	return (o + 1) % overlayMode(len(overlayNames))
+}
+
+// diffColor marks pixels that changed since the previous frame
+var diffColor = color.RGBA{255, 95, 255, 255}
+
+// diffDim is how much unchanged pixels are darkened, out of 256
+const diffDim = 80
+
+// diffFrame is the diff overlay of one frame, kept so that playback with the
+// overlay on does not compare and resize every frame again on each advance
+type diffFrame struct {
+	src  *image.RGBA // The composited frame the diff was made for
+	img  *image.RGBA
+	info string
+
+	// The fitted rendering of img and the view it was rendered for
+	view string
+	key  renderKey
+}
+
+// ============================================================================
+// Frame Difference
+// ============================================================================
+
+// frameDiff returns cur with changed pixels highlighted and unchanged ones
+// dimmed, along with the bounding box and number of changed pixels
+func frameDiff(prev, cur *image.RGBA) (*image.RGBA, image.Rectangle, int) {
+	b := cur.Bounds()
+	out := image.NewRGBA(b)
+	var changed image.Rectangle
+	count := 0
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := cur.PixOffset(x, y)
+			px := cur.Pix[i : i+4 : i+4]
+
+			if image.Pt(x, y).In(prev.Bounds()) {
+				j := prev.PixOffset(x, y)
+				if string(prev.Pix[j:j+4]) == string(px) {
+					out.Pix[i] = uint8(int(px[0]) * diffDim / 256)
+					out.Pix[i+1] = uint8(int(px[1]) * diffDim / 256)
+					out.Pix[i+2] = uint8(int(px[2]) * diffDim / 256)
+					out.Pix[i+3] = px[3]
+					continue
+				}
+			}
+
+			out.SetRGBA(x, y, diffColor)
+			changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			count++
+		}
+	}
+
+	return out, changed, count
+}
+
+// previousFrame returns the frame shown before i, wrapping to the last frame
+// for the first one
+func (m model) previousFrame(i int) int {
+	n := len(m.Composited)
+	return (i - 1 + n) % n
+}
+
+// frameImage returns composited frame i with the active overlay applied and
+// a description of what the overlay found
+func (m *model) frameImage(i int) (*image.RGBA, string) {
+	if m.Overlay != overlayDiff {
+		return m.Composited[i], ""
+	}
+	d := m.cachedDiff(i)
+	return d.img, d.info
+}
+
+// cachedDiff returns the diff overlay of frame i, computing it the first time
+// the frame is shown
+func (m *model) cachedDiff(i int) *diffFrame {
+	if len(m.diffs) != len(m.Composited) {
+		m.diffs = make([]*diffFrame, len(m.Composited))
+	}
+	if d := m.diffs[i]; d != nil && d.src == m.Composited[i] {
+		return d
+	}
+
+	d := &diffFrame{src: m.Composited[i], img: m.Composited[i], info: "diff n/a"}
+	if len(m.Composited) > 1 {
+		prev := m.previousFrame(i)
+		img, changed, count := frameDiff(m.Composited[prev], m.Composited[i])
+		d.img = img
+		d.info = fmt.Sprintf("diff vs %d: %dpx in %v", prev+1, count, changed)
+		if count == 0 {
+			d.info = fmt.Sprintf("diff vs %d: none", prev+1)
+		}
+	}
+	m.diffs[i] = d
+	return d
+}
+
+// fittedDiff returns the diff overlay of frame i rendered to fit the
+// terminal, rendering it again only when the view has changed
+func (m *model) fittedDiff(i int) string {
+	d := m.cachedDiff(i)
+	if key := m.renderKey(); d.view == "" || d.key != key {
+		d.view, d.key = m.renderImageHalfBlock(d.img, nil), key
+	}
+	return d.view
+}
+
+// cycleOverlay moves to the next overlay and re-renders the current frame;
+// diffs are only kept while the diff overlay is on
+func (m *model) cycleOverlay() {
+	m.Overlay = m.Overlay.next()
+	if m.Overlay != overlayDiff {
+		m.diffs = nil
+	}
+	m.refreshViewport()
+}
+
+// overlayLabel describes the active overlay for the status bar
+func (m model) overlayLabel() string {
+	if m.CurrentFrame >= len(m.Composited) {
+		return ""
+	}
+
+	switch m.Overlay {
+	case overlayDiff:
+		return m.OverlayInfo
+
+	case overlayRect:
+		if m.GIF == nil || m.CurrentFrame >= len(m.GIF.Image) {
+			return ""
+		}
+		return fmt.Sprintf("rect %v", m.GIF.Image[m.CurrentFrame].Rect)
+	}
+	return ""
+}
+
+// ============================================================================
+// Sub-rectangle Outline
+// ============================================================================
+
+// rectOutline returns the cells outlining the raw sub-rectangle of the
+// current frame, clipped to what is on screen
+func (m model) rectOutline() (image.Rectangle, bool) {
+	if m.Overlay != overlayRect || m.GIF == nil || m.CurrentFrame >= len(m.GIF.Image) || len(m.Composited) == 0 {
+		return image.Rectangle{}, false
+	}
+
+	src, _, _ := m.viewport()
+	rect := m.GIF.Image[m.CurrentFrame].Rect.Intersect(src)
+	if rect.Empty() {
+		return image.Rectangle{}, false
+	}
+
+	topLeft, _, ok1 := m.sourceToCell(rect.Min)
+	bottomRight, _, ok2 := m.sourceToCell(rect.Max.Sub(image.Pt(1, 1)))
+	if !ok1 || !ok2 {
+		return image.Rectangle{}, false
+	}
+
+	bottomRight.X += m.charsPerPixel() - 1
+	return image.Rectangle{Min: topLeft, Max: bottomRight.Add(image.Pt(1, 1))}, true
+}
+
+// renderRectOutline draws the outline as separate edge layers so that the
+// frame stays visible inside it
+func (m model) renderRectOutline() []*lipgloss.Layer {
+	cells, ok := m.rectOutline()
+	if !ok {
+		return nil
+	}
+
+	style := m.colors().highlight()
+	width, height := cells.Dx(), cells.Dy()
+
+	if width < 2 || height < 2 {
+		return []*lipgloss.Layer{
+			lipgloss.NewLayer(style.Render(strings.Repeat("█", width))).X(cells.Min.X).Y(cells.Min.Y).Z(3),
+		}
+	}
+
+	border := lipgloss.RoundedBorder()
+	top := border.TopLeft + strings.Repeat(border.Top, width-2) + border.TopRight
+	bottom := border.BottomLeft + strings.Repeat(border.Bottom, width-2) + border.BottomRight
+	side := strings.TrimSuffix(strings.Repeat(border.Left+"\n", height-2), "\n")
+
+	layers := []*lipgloss.Layer{
+		lipgloss.NewLayer(style.Render(top)).X(cells.Min.X).Y(cells.Min.Y).Z(3),
+		lipgloss.NewLayer(style.Render(bottom)).X(cells.Min.X).Y(cells.Max.Y - 1).Z(3),
+	}
+	if height > 2 {
+		layers = append(layers,
+			lipgloss.NewLayer(style.Render(side)).X(cells.Min.X).Y(cells.Min.Y+1).Z(3),
+			lipgloss.NewLayer(style.Render(side)).X(cells.Max.X-1).Y(cells.Min.Y+1).Z(3))
+	}
+	return layers
+}
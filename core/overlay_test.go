@@ -0,0 +1,135 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+func TestFrameDiff(t *testing.T) {
+	prev := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	cur := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for i := range prev.Pix {
+		prev.Pix[i] = 200
+		cur.Pix[i] = 200
+	}
+	cur.SetRGBA(1, 0, color.RGBA{0, 0, 0, 255})
+	cur.SetRGBA(2, 2, color.RGBA{0, 0, 0, 255})
+
+	out, changed, count := frameDiff(prev, cur)
+	if count != 2 {
+		t.Errorf("frameDiff() count = %d, want 2", count)
+	}
+	if want := image.Rect(1, 0, 3, 3); changed != want {
+		t.Errorf("frameDiff() changed = %v, want %v", changed, want)
+	}
+	if got := out.RGBAAt(1, 0); got != diffColor {
+		t.Errorf("changed pixel = %v, want %v", got, diffColor)
+	}
+	if got := out.RGBAAt(0, 0); got.R != 200*diffDim/256 || got.A != 200 {
+		t.Errorf("unchanged pixel = %v, want dimmed", got)
+	}
+}
+
+func TestPreviousFrame(t *testing.T) {
+	m := model{player: player{Composited: make([]*image.RGBA, 3)}}
+	for i, want := range []int{2, 0, 1} {
+		if got := m.previousFrame(i); got != want {
+			t.Errorf("previousFrame(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestCycleOverlay(t *testing.T) {
+	m := newZoomModel()
+	m.Composited[1] = image.NewRGBA(m.Composited[0].Bounds())
+	m.CellAspect = 2
+	m.PixelChars = 1
+	m.GIF.Image = []*image.Paletted{
+		image.NewPaletted(image.Rect(0, 0, 200, 100), nil),
+		image.NewPaletted(image.Rect(50, 20, 150, 80), nil),
+	}
+	m.CurrentFrame = 1
+
+	m.cycleOverlay()
+	if m.Overlay != overlayDiff || m.ViewportFrame == "" {
+		t.Fatalf("diff overlay: Overlay = %v, ViewportFrame empty = %v", m.Overlay, m.ViewportFrame == "")
+	}
+	if !strings.HasPrefix(m.overlayLabel(), "diff vs 1: 20000px") {
+		t.Errorf("overlayLabel() = %q, want every pixel changed", m.overlayLabel())
+	}
+
+	m.cycleOverlay()
+	if m.Overlay != overlayRect || m.ViewportFrame != "" {
+		t.Errorf("rect overlay should use the pre-rendered frame")
+	}
+	cells, ok := m.rectOutline()
+	if !ok {
+		t.Fatal("rectOutline() found nothing to outline")
+	}
+
+	// The sub-rect is the middle half of the canvas horizontally
+	bounds := m.imageBounds()
+	if cells.Min.X <= bounds.Min.X || cells.Max.X >= bounds.Max.X || !cells.In(bounds) {
+		t.Errorf("rectOutline() = %v, want strictly inside image %v", cells, bounds)
+	}
+	if got := len(m.renderRectOutline()); got != 4 {
+		t.Errorf("renderRectOutline() = %d layers, want 4 edges", got)
+	}
+
+	m.cycleOverlay()
+	if m.Overlay != overlayNone || m.overlayLabel() != "" {
+		t.Errorf("cycleOverlay() did not wrap back to none")
+	}
+}
+
+func TestRectOutlineOffscreen(t *testing.T) {
+	m := newZoomModel()
+	m.GIF = &gif.GIF{Image: []*image.Paletted{
+		image.NewPaletted(image.Rect(0, 0, 10, 10), nil),
+	}, Delay: []int{10}}
+	m.Overlay = overlayRect
+	m.setZoom(8)
+	m.centerOn(image.Pt(150, 80))
+
+	if _, ok := m.rectOutline(); ok {
+		t.Error("rectOutline() drew a sub-rect that is panned off screen")
+	}
+}
+
+func TestDiffOverlayCached(t *testing.T) {
+	m := newZoomModel()
+	m.Composited[1] = image.NewRGBA(m.Composited[0].Bounds())
+	m.CellAspect = 2
+	m.PixelChars = 1
+	m.cycleOverlay()
+
+	first := m.diffs[0]
+	m.stepFrame(1)
+	m.stepFrame(1)
+	if m.diffs[0] != first || m.diffs[1] == nil {
+		t.Fatal("playing frames again recomputed their diffs")
+	}
+	view := m.ViewportFrame
+
+	// A resize renders the cached diff again without recomputing it
+	m.Width /= 2
+	m.refreshViewport()
+	if m.diffs[0] != first || m.ViewportFrame == view {
+		t.Error("a resize should re-render the cached diff")
+	}
+
+	// Frames rendered again replace the diffs made from the old ones
+	m.Composited[0] = image.NewRGBA(m.Composited[0].Bounds())
+	m.refreshViewport()
+	if m.diffs[0] == first {
+		t.Error("diff kept for a replaced frame")
+	}
+
+	m.cycleOverlay()
+	if m.diffs != nil {
+		t.Error("diffs kept after leaving the diff overlay")
+	}
+}
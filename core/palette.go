@@ -0,0 +1,182 @@
+package jif
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// maxHistogramRows caps the histogram so the palette panel fits most
+// terminals
+const maxHistogramRows = 12
+
+// histogramBarWidth is the width of the longest histogram bar in cells
+const histogramBarWidth = 20
+
+// colourCount is how many pixels of a frame use one palette index
+type colourCount struct {
+	Index int
+	Color color.Color
+	Count int
+}
+
+// ============================================================================
+// Palette Analysis
+// ============================================================================
+
+// paletteHistogram counts the palette indices used by the pixels of a frame,
+// most used first
+func paletteHistogram(frame *image.Paletted) []colourCount {
+	counts := make([]int, 256)
+	r := frame.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := frame.Pix[frame.PixOffset(r.Min.X, y):frame.PixOffset(r.Max.X, y)]
+		for _, index := range row {
+			counts[index]++
+		}
+	}
+
+	var histogram []colourCount
+	for index, count := range counts {
+		if count == 0 {
+			continue
+		}
+		var c color.Color = color.Transparent
+		if index < len(frame.Palette) {
+			c = frame.Palette[index]
+		}
+		histogram = append(histogram, colourCount{Index: index, Color: c, Count: count})
+	}
+
+	slices.SortStableFunc(histogram, func(a, b colourCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return histogram
+}
+
+// ============================================================================
+// Palette View
+// ============================================================================
+
+// swatchesPerRow returns how many palette entries are drawn on each line
+func (m model) swatchesPerRow() int {
+	if m.Width >= 80 {
+		return 32
+	}
+	return 16
+}
+
+// renderSwatch draws one palette entry two cells wide; the transparency
+// index is drawn as a highlighted checker
+func (t theme) renderSwatch(c color.Color, transparent bool) string {
+	if transparent {
+		return t.highlight().Render("░░")
+	}
+	return lipgloss.NewStyle().Background(lipgloss.Color(hexColor(c))).Render("  ")
+}
+
+// renderSwatches draws a palette as a grid of swatches
+func (t theme) renderSwatches(p color.Palette, transparent, perRow int) string {
+	var rows []string
+	for start := 0; start < len(p); start += perRow {
+		var sb strings.Builder
+		for i := start; i < min(len(p), start+perRow); i++ {
+			sb.WriteString(t.renderSwatch(p[i], i == transparent))
+		}
+		rows = append(rows, sb.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderHistogram draws the most used colours of a frame as bars
+func (t theme) renderHistogram(histogram []colourCount, total, transparent, maxRows int) string {
+	if len(histogram) == 0 || total == 0 {
+		return ""
+	}
+
+	label := t.label()
+	highlight := t.highlight()
+
+	var rows []string
+	for _, entry := range histogram[:min(len(histogram), maxRows)] {
+		isTransparent := entry.Index == transparent
+		bar := fmt.Sprintf("%-*s", histogramBarWidth,
+			strings.Repeat("█", max(1, entry.Count*histogramBarWidth/histogram[0].Count)))
+
+		name := hexColor(entry.Color)
+		if isTransparent {
+			name = "transp."
+			bar = highlight.Render(bar)
+		}
+
+		rows = append(rows, fmt.Sprintf("%s %s %-7s %s %s",
+			t.renderSwatch(entry.Color, isTransparent),
+			label.Render(fmt.Sprintf("%3d", entry.Index)),
+			name,
+			bar,
+			label.Render(fmt.Sprintf("%5.1f%%", float64(entry.Count)*100/float64(total)))))
+	}
+
+	if hidden := len(histogram) - maxRows; hidden > 0 {
+		rows = append(rows, label.Render(fmt.Sprintf("… %d more colours", hidden)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m model) renderPalette() string {
+	if m.GIF == nil || m.CurrentFrame >= len(m.GIF.Image) {
+		return ""
+	}
+
+	t := m.colors()
+	title := t.title()
+	label := t.label()
+
+	frame := m.GIF.Image[m.CurrentFrame]
+	global := globalPalette(m.GIF)
+	perRow := m.swatchesPerRow()
+	transparent := transparentIndex(frame.Palette)
+
+	sections := []string{title.Render(fmt.Sprintf("Palette · frame %d", m.CurrentFrame+1)), ""}
+
+	// image/gif clears the transparency entry in a copy of the global
+	// palette, so highlight it there only when this frame uses that palette
+	local := hasLocalPalette(frame, global)
+	globalTransparent := -1
+	if !local {
+		globalTransparent = transparent
+	}
+
+	if global != nil {
+		sections = append(sections,
+			label.Render(fmt.Sprintf("Global (%d colours)", len(global))),
+			t.renderSwatches(global, globalTransparent, perRow), "")
+	} else {
+		sections = append(sections, label.Render("No global palette"), "")
+	}
+
+	if local {
+		sections = append(sections,
+			label.Render(fmt.Sprintf("Local (%d colours)", len(frame.Palette))),
+			t.renderSwatches(frame.Palette, transparent, perRow), "")
+	} else {
+		sections = append(sections, label.Render("Frame uses the global palette"), "")
+	}
+
+	histogram := paletteHistogram(frame)
+	sections = append(sections, label.Render(fmt.Sprintf("Colour usage (%d used)", len(histogram))))
+
+	// Leave room for the border, the status line and everything above
+	used := lipgloss.Height(strings.Join(sections, "\n")) + 4
+	maxRows := min(maxHistogramRows, m.Height-used)
+	if maxRows > 0 {
+		sections = append(sections, t.renderHistogram(histogram, frame.Rect.Dx()*frame.Rect.Dy(), transparent, maxRows))
+	}
+
+	return t.panel().Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
@@ -0,0 +1,84 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPaletteHistogram(t *testing.T) {
+	palette := color.Palette{
+		color.RGBA{0, 0, 0, 0},
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 255, 0, 255},
+	}
+
+	// A sub-image shares Pix with its parent; only its own rect is counted
+	parent := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	for i := range parent.Pix {
+		parent.Pix[i] = 1
+	}
+	frame := parent.SubImage(image.Rect(1, 1, 3, 3)).(*image.Paletted)
+	frame.SetColorIndex(1, 1, 2)
+	frame.SetColorIndex(2, 1, 2)
+	frame.SetColorIndex(1, 2, 2)
+	frame.SetColorIndex(2, 2, 0)
+
+	got := paletteHistogram(frame)
+	want := []colourCount{{Index: 2, Count: 3}, {Index: 0, Count: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("paletteHistogram() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Index != want[i].Index || got[i].Count != want[i].Count {
+			t.Errorf("paletteHistogram()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRenderHistogram(t *testing.T) {
+	histogram := []colourCount{
+		{Index: 4, Color: color.RGBA{255, 0, 0, 255}, Count: 30},
+		{Index: 0, Color: color.RGBA{}, Count: 10},
+		{Index: 7, Color: color.RGBA{0, 0, 255, 255}, Count: 5},
+	}
+
+	out := ansi.Strip(defaultTheme.renderHistogram(histogram, 45, 0, 2))
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("renderHistogram() has %d lines, want 2 rows and a summary:\n%s", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "#ff0000") || !strings.Contains(lines[0], "66.7%") {
+		t.Errorf("first row = %q, want #ff0000 at 66.7%%", lines[0])
+	}
+	if !strings.Contains(lines[1], "transp.") {
+		t.Errorf("second row = %q, want the transparency index marked", lines[1])
+	}
+	if !strings.Contains(lines[2], "1 more") {
+		t.Errorf("summary = %q, want 1 more colour", lines[2])
+	}
+}
+
+func TestRenderPalette(t *testing.T) {
+	g, err := loadGIF("../testdata/multi.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{player: player{GIF: g}, Width: 100, Height: 60}
+	out := ansi.Strip(m.renderPalette())
+	for _, want := range []string{"Palette · frame 1", "Colour usage"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("renderPalette() missing %q:\n%s", want, out)
+		}
+	}
+
+	empty := model{player: player{GIF: &gif.GIF{}}}
+	if got := empty.renderPalette(); got != "" {
+		t.Errorf("renderPalette() without frames = %q, want empty", got)
+	}
+}
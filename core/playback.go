@@ -0,0 +1,127 @@
+package jif
+
+import (
+	"fmt"
+	"image/gif"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+// maxSpeed bounds the playback speed multiplier
+const maxSpeed = 16
+
+// loopForever and loopGIF are the named loop settings; any other setting is
+// a number of plays
+const (
+	loopForever = "forever"
+	loopGIF     = "gif"
+)
+
+// LoopFinite is the loop setting of inline and stream playback when none is
+// given: the GIF's own loop count, except that a GIF looping forever plays
+// once so that the command ends
+const LoopFinite = "finite"
+
+// colorModes maps colour mode names to terminal colour profiles; auto leaves
+// detection to the terminal
+var colorModes = map[string]colorprofile.Profile{
+	"truecolor": colorprofile.TrueColor,
+	"256":       colorprofile.ANSI256,
+	"16":        colorprofile.ANSI,
+}
+
+// ============================================================================
+// Settings
+// ============================================================================
+
+// parseSpeed validates a playback speed multiplier; zero means normal speed
+func parseSpeed(speed float64) (float64, error) {
+	if speed == 0 {
+		return 1, nil
+	}
+	if speed < 0 || speed > maxSpeed {
+		return 0, fmt.Errorf("invalid speed %g (want a multiplier between 0 and %d)", speed, maxSpeed)
+	}
+	return speed, nil
+}
+
+// parseLoop validates a loop setting: forever, gif to honour the file's
+// loop count, or a number of plays. Empty means forever.
+func parseLoop(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return loopForever, nil
+	case loopForever, loopGIF, LoopFinite:
+		return s, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid loop %q (want forever, gif or a number of plays)", s)
+}
+
+// parseColorMode returns the colour profile to force, if any
+func parseColorMode(s string) (colorprofile.Profile, bool, error) {
+	s = strings.ToLower(s)
+	if s == "" || s == "auto" {
+		return 0, false, nil
+	}
+	if profile, ok := colorModes[s]; ok {
+		return profile, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid colour mode %q (want auto, truecolor, 256 or 16)", s)
+}
+
+// ============================================================================
+// Playback
+// ============================================================================
+
+// playLimit returns how many times g plays before stopping, or 0 to play
+// forever
+func playLimit(loop string, g *gif.GIF) int {
+	switch loop {
+	case "", loopForever:
+		return 0
+	case loopGIF, LoopFinite:
+		switch {
+		case g == nil:
+			return 0
+		case g.LoopCount == 0 && loop == LoopFinite:
+			return 1
+		case g.LoopCount == 0:
+			return 0
+		case g.LoopCount < 0:
+			return 1
+		}
+		return g.LoopCount + 1
+	}
+	n, _ := strconv.Atoi(loop)
+	return n
+}
+
+// playbackDelay returns how long frame i of g stays on screen at speed
+func playbackDelay(g *gif.GIF, i int, speed float64) time.Duration {
+	delay := frameDelay(g, i)
+	if speed <= 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) / speed)
+}
+
+// finished reports whether playback stopped after the last allowed play
+func (m model) finished() bool {
+	limit := playLimit(m.Loop, m.GIF)
+	return limit > 0 && m.Plays >= limit
+}
+
+// speedLabel describes a non-default playback speed for the status bar
+func (m model) speedLabel() string {
+	if m.Speed == 0 || m.Speed == 1 {
+		return ""
+	}
+	return strconv.FormatFloat(m.Speed, 'g', -1, 64) + "x"
+}
@@ -0,0 +1,96 @@
+package jif
+
+import (
+	"image/gif"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseLoop(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "forever", false},
+		{"Forever", "forever", false},
+		{"gif", "gif", false},
+		{"3", "3", false},
+		{"0", "", true},
+		{"-1", "", true},
+		{"sometimes", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLoop(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLoop(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLoop(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlayLimit(t *testing.T) {
+	tests := []struct {
+		loop      string
+		loopCount int
+		want      int
+	}{
+		{"forever", -1, 0},
+		{"gif", 0, 0},
+		{"gif", -1, 1},
+		{"gif", 2, 3},
+		{"finite", 0, 1},
+		{"finite", -1, 1},
+		{"finite", 2, 3},
+		{"4", 0, 4},
+	}
+
+	for _, tt := range tests {
+		g := &gif.GIF{LoopCount: tt.loopCount}
+		if got := playLimit(tt.loop, g); got != tt.want {
+			t.Errorf("playLimit(%q, LoopCount %d) = %d, want %d", tt.loop, tt.loopCount, got, tt.want)
+		}
+	}
+}
+
+func TestPlaybackDelay(t *testing.T) {
+	g := &gif.GIF{Delay: []int{10}}
+	if got := playbackDelay(g, 0, 2); got != 50*time.Millisecond {
+		t.Errorf("playbackDelay(speed 2) = %v, want 50ms", got)
+	}
+	if got := playbackDelay(g, 0, 0.5); got != 200*time.Millisecond {
+		t.Errorf("playbackDelay(speed 0.5) = %v, want 200ms", got)
+	}
+}
+
+func TestLoopLimitStopsPlayback(t *testing.T) {
+	m := &model{
+		player: player{GIF: &gif.GIF{Delay: []int{10, 10}}, Frames: []string{"a", "b"}},
+		Ready:  true,
+		Loop:   "2",
+	}
+
+	// Two plays of two frames stop on the last frame
+	for range 3 {
+		m.handleFrameAdvance()
+	}
+	if m.Paused || m.CurrentFrame != 1 {
+		t.Fatalf("after 3 advances: Paused = %v, frame %d, want playing frame 1", m.Paused, m.CurrentFrame)
+	}
+	m.handleFrameAdvance()
+	if !m.Paused || m.CurrentFrame != 1 || !m.finished() {
+		t.Fatalf("after the last play: Paused = %v, frame %d, want paused on frame 1", m.Paused, m.CurrentFrame)
+	}
+
+	// Resuming starts over
+	m.Update(tea.KeyPressMsg{Code: tea.KeySpace})
+	if m.Paused || m.CurrentFrame != 0 || m.Plays != 0 {
+		t.Errorf("after resuming: Paused = %v, frame %d, plays %d, want playing from 0", m.Paused, m.CurrentFrame, m.Plays)
+	}
+}
@@ -0,0 +1,352 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// fileLoadedMsg carries a GIF decoded, and for playlist entries rendered, in
+// the background
+type fileLoadedMsg struct {
+	source string
+	loaded loadedGIF
+	err    error
+}
+
+// loadedGIF is a decoded playlist entry kept for instant switching. Frames
+// rendered for it are reused while the view they were rendered for, key,
+// has not changed.
+type loadedGIF struct {
+	gif        *gif.GIF
+	stats      loadStats
+	frames     []string
+	composited []*image.RGBA
+	renderTime time.Duration
+	key        renderKey
+}
+
+// renderKey is the view state that rendered frames depend on
+type renderKey struct {
+	Width, Height int
+	PixelChars    int
+	Stretch       float64
+	Fit           fitMode
+	Background    background
+	Terminal      color.RGBA64
+}
+
+// rendered reports whether l holds frames rendered for key
+func (l loadedGIF) rendered(key renderKey) bool {
+	return l.gif != nil && len(l.frames) == len(l.gif.Image) && l.key == key
+}
+
+// ============================================================================
+// Source Expansion
+// ============================================================================
+
+// isGIFFile reports whether a path has a .gif extension
+func isGIFFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gif")
+}
+
+// hasGlobMeta reports whether a path contains glob wildcards
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// listGIFs returns the GIF files directly inside dir, sorted by name
+func listGIFs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isGIFFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// expandSources turns command line arguments into a playlist: URLs and files
+// are kept as given, globs are expanded and directories contribute their GIFs
+func expandSources(args []string) ([]string, error) {
+	var sources []string
+
+	for _, arg := range args {
+		switch {
+		case isURL(arg):
+			sources = append(sources, arg)
+
+		case hasGlobMeta(arg):
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && !info.IsDir() {
+					sources = append(sources, match)
+				}
+			}
+
+		default:
+			info, err := os.Stat(arg)
+			if err == nil && info.IsDir() {
+				files, err := listGIFs(arg)
+				if err != nil {
+					return nil, err
+				}
+				sources = append(sources, files...)
+				continue
+			}
+			// Missing files are reported when they are opened
+			sources = append(sources, arg)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no GIF files found in %s", strings.Join(args, ", "))
+	}
+	return sources, nil
+}
+
+// ============================================================================
+// Playlist Navigation
+// ============================================================================
+
+// renderKey returns the view state the frames are rendered for
+func (m model) renderKey() renderKey {
+	key := renderKey{
+		Width:      m.Width,
+		Height:     m.imageHeight(),
+		PixelChars: m.charsPerPixel(),
+		Stretch:    m.pixelStretch(),
+		Fit:        m.Fit,
+		Background: m.Background,
+	}
+	if m.TerminalBackground != nil {
+		key.Terminal = color.RGBA64Model.Convert(m.TerminalBackground).(color.RGBA64)
+	}
+	return key
+}
+
+// renderer returns a copy of the view settings that frames are rendered
+// with, safe to use in the background while m changes
+func (m model) renderer() *model {
+	return &model{
+		Width:              m.Width,
+		Height:             m.Height,
+		Fit:                m.Fit,
+		CellAspect:         m.CellAspect,
+		PixelChars:         m.PixelChars,
+		Background:         m.Background,
+		TerminalBackground: m.TerminalBackground,
+		ShowFilmstrip:      m.ShowFilmstrip,
+	}
+}
+
+// loadFile decodes a playlist entry in the background, unless cached holds
+// it already, and renders its frames for the current view
+func (m model) loadFile(source string, cached *loadedGIF) tea.Cmd {
+	r := m.renderer()
+	return func() tea.Msg {
+		var loaded loadedGIF
+		if cached != nil {
+			loaded = *cached
+		} else {
+			g, stats, err := loadGIFWithStats(source)
+			if err != nil {
+				return fileLoadedMsg{source: source, err: err}
+			}
+			loaded = loadedGIF{gif: g, stats: stats}
+		}
+
+		start := time.Now()
+		loaded.frames, loaded.composited = r.renderFrames(loaded.gif, nil)
+		loaded.renderTime = time.Since(start)
+		loaded.key = r.renderKey()
+		return fileLoadedMsg{source: source, loaded: loaded}
+	}
+}
+
+// currentSource returns the playlist entry being shown
+func (m model) currentSource() string {
+	if m.PlaylistIndex < len(m.Playlist) {
+		return m.Playlist[m.PlaylistIndex]
+	}
+	return ""
+}
+
+// playlistIndex wraps i into the playlist
+func (m model) playlistIndex(i int) int {
+	n := len(m.Playlist)
+	return ((i % n) + n) % n
+}
+
+// switchFile moves delta entries through the playlist
+func (m *model) switchFile(delta int) tea.Cmd {
+	if len(m.Playlist) < 2 {
+		return nil
+	}
+	return m.showFile(m.playlistIndex(m.PlaylistIndex + delta))
+}
+
+// showFile shows playlist entry i: straight away when its frames were
+// rendered in the background, after rendering when only decoded, and loading
+// it in the background otherwise
+func (m *model) showFile(i int) tea.Cmd {
+	// Frames are rendered in place, so wait for the current render to finish
+	if m.Loading {
+		m.queuedFile, m.fileQueued = i, true
+		return nil
+	}
+
+	m.PlaylistIndex = i
+	source := m.currentSource()
+	m.LoadError = ""
+
+	if loaded, ok := m.preloaded[source]; ok {
+		m.pendingSource = ""
+		return m.showLoaded(loaded)
+	}
+
+	m.pendingSource = source
+	m.Ready = false
+	m.Frames = []string{}
+	if m.preloading[source] {
+		return nil
+	}
+	m.markPreloading(source)
+	return m.loadFile(source, nil)
+}
+
+// showLoaded switches to a loaded GIF, reusing its frames when they were
+// rendered for the current view
+func (m *model) showLoaded(loaded loadedGIF) tea.Cmd {
+	m.setGIF(loaded.gif, loaded.stats)
+	if !loaded.rendered(m.renderKey()) {
+		return m.reprocess()
+	}
+
+	m.Frames = loaded.frames
+	m.Composited = loaded.composited
+	m.RenderTime = loaded.renderTime
+	m.Thumbnails = nil
+	_, cmd := m.handleProcessingComplete()
+	return cmd
+}
+
+// setGIF replaces the GIF being viewed and resets per-file view state
+func (m *model) setGIF(g *gif.GIF, stats loadStats) {
+	m.GIF = g
+	m.Stats = stats
+	m.Composited = nil
+	m.diffs = nil
+	m.CurrentFrame = 0
+	m.PixelInfo = ""
+	m.ViewportFrame = ""
+	m.PanX, m.PanY = 0, 0
+}
+
+// handleFileLoaded caches a loaded GIF and shows it if it was requested. A
+// file that fails to load replaces the one shown with an error, so nothing
+// of the previous file is left under the new name.
+func (m *model) handleFileLoaded(msg fileLoadedMsg) (tea.Model, tea.Cmd) {
+	delete(m.preloading, msg.source)
+
+	if msg.err == nil {
+		if m.preloaded == nil {
+			m.preloaded = make(map[string]loadedGIF)
+		}
+		m.preloaded[msg.source] = msg.loaded
+	}
+
+	if msg.source != m.pendingSource {
+		return m, nil
+	}
+	m.pendingSource = ""
+
+	if msg.err != nil {
+		m.LoadError = fmt.Sprintf("%s: %v", filepath.Base(msg.source), msg.err)
+		m.setGIF(nil, loadStats{})
+		return m, nil
+	}
+	return m, m.showLoaded(msg.loaded)
+}
+
+// preloadNeighbours loads and renders the previous and next playlist entries
+// in the background, so that switching to them is instant, and drops cached
+// GIFs that are no longer adjacent. Neighbours rendered for an earlier view
+// are rendered again.
+func (m *model) preloadNeighbours() tea.Cmd {
+	if len(m.Playlist) < 2 {
+		return nil
+	}
+
+	key := m.renderKey()
+	keep := map[string]bool{m.currentSource(): true}
+	if m.preloaded == nil {
+		m.preloaded = make(map[string]loadedGIF)
+	}
+	m.preloaded[m.currentSource()] = loadedGIF{
+		gif:        m.GIF,
+		stats:      m.Stats,
+		frames:     m.Frames,
+		composited: m.Composited,
+		renderTime: m.RenderTime,
+		key:        key,
+	}
+
+	var cmds []tea.Cmd
+	for _, delta := range []int{1, -1} {
+		source := m.Playlist[m.playlistIndex(m.PlaylistIndex+delta)]
+		keep[source] = true
+
+		loaded, ok := m.preloaded[source]
+		if ok && loaded.rendered(key) || m.preloading[source] {
+			continue
+		}
+		var cached *loadedGIF
+		if ok {
+			cached = &loaded
+		}
+		m.markPreloading(source)
+		cmds = append(cmds, m.loadFile(source, cached))
+	}
+
+	for source := range m.preloaded {
+		if !keep[source] {
+			delete(m.preloaded, source)
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// markPreloading records that source is being decoded
+func (m *model) markPreloading(source string) {
+	if m.preloading == nil {
+		m.preloading = make(map[string]bool)
+	}
+	m.preloading[source] = true
+}
+
+// playlistLabel describes the playlist position for the status bar
+func (m model) playlistLabel() string {
+	if len(m.Playlist) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("file %d/%d %s", m.PlaylistIndex+1, len(m.Playlist), filepath.Base(m.currentSource()))
+}
@@ -0,0 +1,242 @@
+package jif
+
+import (
+	"errors"
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestExpandSources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.gif", "a.gif", "notes.txt", "C.GIF"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.gif"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "directory lists GIFs by name",
+			args: []string{dir},
+			want: []string{filepath.Join(dir, "C.GIF"), filepath.Join(dir, "a.gif"), filepath.Join(dir, "b.gif")},
+		},
+		{
+			name: "glob is expanded and sorted",
+			args: []string{filepath.Join(dir, "*.gif")},
+			want: []string{filepath.Join(dir, "a.gif"), filepath.Join(dir, "b.gif")},
+		},
+		{
+			name: "files and URLs are kept in order",
+			args: []string{"https://example.com/x.gif", filepath.Join(dir, "b.gif")},
+			want: []string{"https://example.com/x.gif", filepath.Join(dir, "b.gif")},
+		},
+		{
+			name:    "empty glob is an error",
+			args:    []string{filepath.Join(dir, "*.png")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandSources(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newPlaylistModel() *model {
+	g := &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 4, 4), nil)}, Delay: []int{10}}
+	return &model{
+		player:   player{GIF: g, Frames: []string{"frame1"}},
+		Ready:    true,
+		Playlist: []string{"one.gif", "two.gif", "three.gif"},
+	}
+}
+
+func TestSwitchFile(t *testing.T) {
+	t.Run("decoded file is rendered on switching", func(t *testing.T) {
+		m := newPlaylistModel()
+		next := &gif.GIF{Delay: []int{10}}
+		m.preloaded = map[string]loadedGIF{"two.gif": {gif: next}}
+
+		_, cmd := m.Update(tea.KeyPressMsg{Code: ']', Text: "]"})
+		if m.PlaylistIndex != 1 || m.GIF != next {
+			t.Errorf("] should show the preloaded next file, index = %d", m.PlaylistIndex)
+		}
+		if cmd == nil || !m.Loading {
+			t.Error("switching should render the new file")
+		}
+	})
+
+	t.Run("rendered file switches immediately", func(t *testing.T) {
+		m := newPlaylistModel()
+		next := &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 4, 4), nil)}, Delay: []int{10}}
+		m.preloaded = map[string]loadedGIF{"two.gif": {
+			gif:        next,
+			frames:     []string{"next1"},
+			composited: []*image.RGBA{image.NewRGBA(image.Rect(0, 0, 4, 4))},
+			key:        m.renderKey(),
+		}}
+
+		m.Update(tea.KeyPressMsg{Code: ']', Text: "]"})
+		if m.GIF != next || m.Loading || !m.Ready {
+			t.Fatalf("switching to a rendered file should not render again, Loading = %v", m.Loading)
+		}
+		if got := m.currentFrameView(); got != "next1" {
+			t.Errorf("currentFrameView() = %q, want the cached frame", got)
+		}
+	})
+
+	t.Run("frames rendered for another view are not reused", func(t *testing.T) {
+		m := newPlaylistModel()
+		next := &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 4, 4), nil)}, Delay: []int{10}}
+		key := m.renderKey()
+		key.Width++
+		m.preloaded = map[string]loadedGIF{"two.gif": {gif: next, frames: []string{"stale"}, key: key}}
+
+		m.Update(tea.KeyPressMsg{Code: ']', Text: "]"})
+		if !m.Loading {
+			t.Error("frames rendered at another size should be rendered again")
+		}
+	})
+
+	t.Run("missing file loads in background", func(t *testing.T) {
+		m := newPlaylistModel()
+
+		_, cmd := m.Update(tea.KeyPressMsg{Code: '[', Text: "["})
+		if m.PlaylistIndex != 2 {
+			t.Errorf("[ should wrap to the last file, index = %d", m.PlaylistIndex)
+		}
+		if m.pendingSource != "three.gif" || m.Ready {
+			t.Errorf("pendingSource = %q, Ready = %v", m.pendingSource, m.Ready)
+		}
+		if cmd == nil {
+			t.Error("switching should start loading the file")
+		}
+
+		loaded := &gif.GIF{Delay: []int{10}}
+		_, cmd = m.Update(fileLoadedMsg{source: "three.gif", loaded: loadedGIF{gif: loaded}})
+		if m.GIF != loaded || m.pendingSource != "" {
+			t.Error("the requested file should be shown once loaded")
+		}
+		if cmd == nil {
+			t.Error("a loaded file should be rendered")
+		}
+	})
+
+	t.Run("load errors are reported", func(t *testing.T) {
+		m := newPlaylistModel()
+		_, _ = m.Update(tea.KeyPressMsg{Code: ']', Text: "]"})
+		_, _ = m.Update(fileLoadedMsg{source: "two.gif", err: errors.New("boom")})
+
+		if m.LoadError == "" {
+			t.Error("a failed load should set LoadError")
+		}
+		// Nothing of the previous file may be re-rendered under the new name
+		if m.GIF != nil || m.Ready {
+			t.Error("a failed load should clear the previous file")
+		}
+		if _, cmd := m.Update(tea.WindowSizeMsg{Width: 100, Height: 50}); cmd != nil && m.Loading {
+			t.Error("resizing after a failed load should not render anything")
+		}
+	})
+
+	t.Run("queued while rendering", func(t *testing.T) {
+		m := newPlaylistModel()
+		m.Loading = true
+
+		if cmd := m.switchFile(1); cmd != nil || m.PlaylistIndex != 0 {
+			t.Error("switching should wait for the current render")
+		}
+
+		_, cmd := m.handleProcessingComplete()
+		if m.PlaylistIndex != 1 || m.pendingSource != "two.gif" || cmd == nil {
+			t.Errorf("the queued file should load once rendering finishes, index = %d", m.PlaylistIndex)
+		}
+	})
+}
+
+func TestPreloadNeighbours(t *testing.T) {
+	m := newPlaylistModel()
+	m.Playlist = []string{"one.gif", "two.gif", "three.gif", "four.gif"}
+	rendered := loadedGIF{gif: m.GIF, frames: []string{"frame1"}, key: m.renderKey()}
+	m.preloaded = map[string]loadedGIF{"four.gif": rendered, "three.gif": rendered}
+
+	if cmd := m.preloadNeighbours(); cmd == nil {
+		t.Fatal("preloadNeighbours() should load the next file")
+	}
+	if !m.preloading["two.gif"] {
+		t.Error("next file should be preloading")
+	}
+	if m.preloading["four.gif"] {
+		t.Error("already cached previous file should not be loaded again")
+	}
+	if _, ok := m.preloaded["three.gif"]; ok {
+		t.Error("files that are not adjacent should be dropped from the cache")
+	}
+	if _, ok := m.preloaded["one.gif"]; !ok {
+		t.Error("the current file should be cached for switching back")
+	}
+
+	// Neighbours rendered for another view are rendered again from the cache
+	m.Width = 100
+	if cmd := m.preloadNeighbours(); cmd == nil || !m.preloading["four.gif"] {
+		t.Error("a neighbour rendered at another size should be rendered again")
+	}
+
+	// A background result for a neighbour is cached without being shown
+	g := &gif.GIF{}
+	_, _ = m.Update(fileLoadedMsg{source: "two.gif", loaded: loadedGIF{gif: g}})
+	if m.preloaded["two.gif"].gif != g || m.GIF == g {
+		t.Error("preloaded file should be cached but not shown")
+	}
+}
+
+func TestPlaylistLabel(t *testing.T) {
+	m := newPlaylistModel()
+	m.PlaylistIndex = 1
+	if got, want := m.playlistLabel(), "file 2/3 two.gif"; got != want {
+		t.Errorf("playlistLabel() = %q, want %q", got, want)
+	}
+
+	m.Playlist = m.Playlist[:1]
+	if got := m.playlistLabel(); got != "" {
+		t.Errorf("single file should have no label, got %q", got)
+	}
+}
+
+func TestLoadFileRenders(t *testing.T) {
+	m := &model{Width: 40, Height: 20}
+	msg, ok := m.loadFile("../testdata/simple.gif", nil)().(fileLoadedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("loadFile() = %+v", msg)
+	}
+	if !msg.loaded.rendered(m.renderKey()) || msg.loaded.frames[0] == "" {
+		t.Errorf("loadFile() should render every frame for the current view")
+	}
+
+	msg = m.loadFile("../testdata/missing.gif", nil)().(fileLoadedMsg)
+	if msg.err == nil {
+		t.Error("loadFile() of a missing file should fail")
+	}
+}
@@ -0,0 +1,230 @@
+package jif
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// maxPaletteSize is the most colours a GIF frame can hold
+const maxPaletteSize = 256
+
+// opaqueThreshold is the alpha below which a pixel is written as transparent
+const opaqueThreshold = 0x80
+
+// ============================================================================
+// Quantization
+// ============================================================================
+
+// colorCount is a distinct colour and how many pixels use it
+type colorCount struct {
+	Color color.NRGBA
+	Count int
+}
+
+// colorBox is a group of colours that median cut splits until there is one
+// box per palette entry
+type colorBox []colorCount
+
+// channel returns channel c (0 red, 1 green, 2 blue) of col
+func channel(col color.NRGBA, c int) uint8 {
+	switch c {
+	case 0:
+		return col.R
+	case 1:
+		return col.G
+	}
+	return col.B
+}
+
+// widest returns the channel with the largest spread in b and that spread
+func (b colorBox) widest() (int, int) {
+	best, spread := 0, -1
+	for c := range 3 {
+		lo, hi := uint8(255), uint8(0)
+		for _, cc := range b {
+			v := channel(cc.Color, c)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if int(hi)-int(lo) > spread {
+			best, spread = c, int(hi)-int(lo)
+		}
+	}
+	return best, spread
+}
+
+// split divides b at the pixel-weighted median of its widest channel
+func (b colorBox) split() (colorBox, colorBox) {
+	c, _ := b.widest()
+	slices.SortStableFunc(b, func(x, y colorCount) int {
+		return cmp.Compare(channel(x.Color, c), channel(y.Color, c))
+	})
+
+	total := 0
+	for _, cc := range b {
+		total += cc.Count
+	}
+	at, seen := 1, 0
+	for i, cc := range b[:len(b)-1] {
+		seen += cc.Count
+		at = i + 1
+		if seen*2 >= total {
+			break
+		}
+	}
+	return b[:at], b[at:]
+}
+
+// average returns the pixel-weighted mean colour of b
+func (b colorBox) average() color.NRGBA {
+	var r, g, bl, n int
+	for _, cc := range b {
+		r += int(cc.Color.R) * cc.Count
+		g += int(cc.Color.G) * cc.Count
+		bl += int(cc.Color.B) * cc.Count
+		n += cc.Count
+	}
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: 0xff}
+}
+
+// medianCut reduces colors to at most n representative colours
+func medianCut(colors []colorCount, n int) []color.Color {
+	boxes := []colorBox{colors}
+	for len(boxes) < n {
+		// Split the box whose widest channel spreads furthest
+		best, bestSpread := -1, 0
+		for i, b := range boxes {
+			if len(b) < 2 {
+				continue
+			}
+			if _, spread := b.widest(); spread > bestSpread {
+				best, bestSpread = i, spread
+			}
+		}
+		if best < 0 {
+			break
+		}
+		lo, hi := boxes[best].split()
+		boxes = append(boxes[:best], append([]colorBox{lo, hi}, boxes[best+1:]...)...)
+	}
+
+	palette := make([]color.Color, len(boxes))
+	for i, b := range boxes {
+		palette[i] = b.average()
+	}
+	return palette
+}
+
+// nearest returns the index of the palette entry closest to c, skipping
+// the transparent entry at skip
+func nearest(palette color.Palette, c color.NRGBA, skip int) int {
+	best, bestDist := 0, -1
+	for i, p := range palette {
+		if i == skip {
+			continue
+		}
+		pc := p.(color.NRGBA)
+		dr, dg, db := int(pc.R)-int(c.R), int(pc.G)-int(c.G), int(pc.B)-int(c.B)
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// histogram counts the opaque colours of imgs and reports whether any pixel
+// is mostly transparent
+func histogram(imgs ...image.Image) (map[color.NRGBA]int, bool) {
+	counts := make(map[color.NRGBA]int)
+	transparent := false
+	for _, img := range imgs {
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				if c.A < opaqueThreshold {
+					transparent = true
+					continue
+				}
+				c.A = 0xff
+				counts[c]++
+			}
+		}
+	}
+	return counts, transparent
+}
+
+// buildPalette picks at most maxColors colours for counts, keeping them
+// exactly when there are few enough and using median cut otherwise. When
+// transparent is set, index 0 is a transparent entry.
+func buildPalette(counts map[color.NRGBA]int, transparent bool, maxColors int) color.Palette {
+	maxColors = max(2, min(maxColors, maxPaletteSize))
+
+	// Sorted so that the palette does not depend on map order
+	colors := make([]colorCount, 0, len(counts))
+	for c, n := range counts {
+		colors = append(colors, colorCount{c, n})
+	}
+	slices.SortFunc(colors, func(a, b colorCount) int {
+		return cmp.Or(
+			cmp.Compare(b.Count, a.Count),
+			cmp.Compare(a.Color.R, b.Color.R),
+			cmp.Compare(a.Color.G, b.Color.G),
+			cmp.Compare(a.Color.B, b.Color.B),
+		)
+	})
+
+	var palette color.Palette
+	slots := maxColors
+	if transparent {
+		palette = append(palette, color.NRGBA{})
+		slots--
+	}
+	if len(colors) <= slots {
+		for _, cc := range colors {
+			palette = append(palette, cc.Color)
+		}
+	} else if slots > 0 {
+		palette = append(palette, medianCut(colors, slots)...)
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.NRGBA{A: 0xff})
+	}
+	return palette
+}
+
+// remap converts img to palette, which buildPalette made; mostly
+// transparent pixels use its transparent entry
+func remap(img image.Image, palette color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	skip := transparentIndex(palette)
+
+	out := image.NewPaletted(bounds, palette)
+	index := make(map[color.NRGBA]uint8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < opaqueThreshold && skip >= 0 {
+				out.SetColorIndex(x, y, uint8(skip))
+				continue
+			}
+			c.A = 0xff
+			i, ok := index[c]
+			if !ok {
+				i = uint8(nearest(palette, c, skip))
+				index[c] = i
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+	return out
+}
+
+// quantize converts img to a paletted image of at most maxColors colours,
+// keeping them exactly when there are few enough and using median cut
+// otherwise. Mostly transparent pixels share a transparent entry at index 0.
+func quantize(img image.Image, maxColors int) *image.Paletted {
+	counts, transparent := histogram(img)
+	return remap(img, buildPalette(counts, transparent, maxColors))
+}
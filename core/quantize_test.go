@@ -0,0 +1,56 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestQuantizeKeepsFewColours(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{G: 255, A: 255})
+	// (2, 0) stays transparent
+
+	p := quantize(img, maxPaletteSize)
+	if len(p.Palette) != 3 {
+		t.Fatalf("palette has %d colours, want 3", len(p.Palette))
+	}
+	if got := transparentIndex(p.Palette); got != 0 {
+		t.Errorf("transparent index = %d, want 0", got)
+	}
+	for x, want := range []color.NRGBA{{R: 255, A: 255}, {G: 255, A: 255}, {}} {
+		if got := color.NRGBAModel.Convert(p.At(x, 0)); got != want {
+			t.Errorf("pixel %d = %v, want %v", x, got, want)
+		}
+	}
+}
+
+func TestQuantizeReducesColours(t *testing.T) {
+	// A red and a blue gradient, far more colours than allowed
+	img := image.NewRGBA(image.Rect(0, 0, 64, 2))
+	for x := range 64 {
+		img.Set(x, 0, color.RGBA{R: uint8(128 + x*2), A: 255})
+		img.Set(x, 1, color.RGBA{B: uint8(128 + x*2), A: 255})
+	}
+
+	p := quantize(img, 8)
+	if len(p.Palette) > 8 {
+		t.Fatalf("palette has %d colours, want at most 8", len(p.Palette))
+	}
+	if transparentIndex(p.Palette) >= 0 {
+		t.Errorf("opaque image got a transparent palette entry")
+	}
+
+	// Every pixel keeps its hue
+	for x := range 64 {
+		r, _, b, _ := p.At(x, 0).RGBA()
+		if r <= b {
+			t.Errorf("red pixel %d became %v", x, p.At(x, 0))
+		}
+		r, _, b, _ = p.At(x, 1).RGBA()
+		if b <= r {
+			t.Errorf("blue pixel %d became %v", x, p.At(x, 1))
+		}
+	}
+}
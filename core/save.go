@@ -0,0 +1,230 @@
+package jif
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// savedMsg reports the end of a save started from the viewer
+type savedMsg struct {
+	path  string
+	stats EditStats
+	err   error
+}
+
+// ============================================================================
+// Save As
+// ============================================================================
+
+// saveSpec is a parsed save-as prompt
+type saveSpec struct {
+	Path    string
+	Options EditOptions
+	Force   bool // Overwrite Path if it exists
+}
+
+// splitSpecWords splits the save-as prompt into words; single or double
+// quotes keep spaces inside a word, as in "my clip.gif" scale=50%
+func splitSpecWords(spec string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+	)
+	for _, r := range spec {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("missing closing %c", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// quoteSpecWord quotes a path for the save-as prompt if it contains spaces
+// or quotes
+func quoteSpecWord(s string) string {
+	if !strings.ContainsAny(s, " \t\"'") {
+		return s
+	}
+	if strings.Contains(s, `"`) {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+// parseEditSpec parses the save-as prompt: the output path followed by edits
+// written as key=value words, e.g. "clip.gif range=10:20 scale=50% reverse".
+// The result is optimised unless optimize=false is given, and an existing
+// file is only replaced when the word force is given.
+func parseEditSpec(spec string) (saveSpec, error) {
+	fields, err := splitSpecWords(spec)
+	if err != nil {
+		return saveSpec{}, err
+	}
+	if len(fields) == 0 || fields[0] == "" {
+		return saveSpec{}, fmt.Errorf("no file name given")
+	}
+
+	parsed := saveSpec{Path: fields[0], Options: EditOptions{Optimize: true}}
+	opts := &parsed.Options
+	for _, field := range fields[1:] {
+		name, value, _ := strings.Cut(field, "=")
+		var err error
+		switch strings.ToLower(name) {
+		case "range":
+			opts.Range = value
+		case "crop":
+			opts.Crop = value
+		case "scale":
+			opts.Scale = value
+		case "filter":
+			opts.Filter = value
+		case "reverse":
+			opts.Reverse = true
+		case "delay":
+			opts.Delay, err = time.ParseDuration(value)
+		case "speed":
+			opts.Speed, err = strconv.ParseFloat(value, 64)
+		case "loop":
+			opts.Loop = value
+		case "optimize":
+			opts.Optimize, err = strconv.ParseBool(cmp.Or(value, "true"))
+		case "colors":
+			opts.Colors, err = strconv.Atoi(value)
+		case "force":
+			parsed.Force = true
+		default:
+			return saveSpec{}, fmt.Errorf("unknown edit %q (want range, crop, scale, filter, reverse, delay, speed, loop, optimize, colors or force)", name)
+		}
+		if err != nil {
+			return saveSpec{}, fmt.Errorf("invalid %s %q", name, value)
+		}
+	}
+	return parsed, nil
+}
+
+// defaultSavePath suggests a file next to source, or in the working
+// directory for URLs, that does not overwrite it
+func defaultSavePath(source string) string {
+	dir, base := filepath.Split(source)
+	if isURL(source) {
+		dir, base = "", "download.gif"
+		if u, err := url.Parse(source); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+			base = path.Base(u.Path)
+		}
+	}
+	return dir + strings.TrimSuffix(base, filepath.Ext(base)) + "-edited.gif"
+}
+
+// openSavePrompt starts the save-as prompt, keeping the text of a failed
+// save so that it can be corrected
+func (m *model) openSavePrompt() {
+	if !m.Ready || m.GIF == nil {
+		return
+	}
+	m.Saving = true
+	if m.SaveInput != "" {
+		return
+	}
+
+	m.SaveInput = quoteSpecWord(defaultSavePath(m.currentSource()))
+	if label := m.speedLabel(); label != "" {
+		m.SaveInput += " speed=" + strings.TrimSuffix(label, "x")
+	}
+}
+
+// startSave writes the edited GIF in the background
+func (m *model) startSave() tea.Cmd {
+	m.Saving = false
+	spec, err := parseEditSpec(m.SaveInput)
+	if err != nil {
+		m.SaveStatus = "Save failed: " + err.Error()
+		return nil
+	}
+
+	// Never replace a file, such as the one being viewed, by accident
+	if _, err := os.Stat(spec.Path); err == nil && !spec.Force {
+		m.SaveStatus = fmt.Sprintf("Save failed: %s exists (add force to replace it)", spec.Path)
+		return nil
+	}
+
+	g, before := m.GIF, m.Stats.FileSize
+	out, opts := spec.Path, spec.Options
+	m.SaveStatus = "Saving " + out + "..."
+	return func() tea.Msg {
+		stats, err := saveEdited(g, out, opts, before)
+		return savedMsg{path: out, stats: stats, err: err}
+	}
+}
+
+func (m *model) handleSaved(msg savedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.SaveStatus = "Save failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.SaveStatus = fmt.Sprintf("Saved %s: %s", msg.path, msg.stats)
+	m.SaveInput = ""
+	return m, nil
+}
+
+// handleSaveKey edits the save-as prompt; it is a text field, so no other
+// bindings apply while it is open
+func (m *model) handleSaveKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.Saving = false
+	case "enter":
+		return m.startSave()
+	case "backspace":
+		if runes := []rune(m.SaveInput); len(runes) > 0 {
+			m.SaveInput = string(runes[:len(runes)-1])
+		}
+	case "ctrl+u":
+		m.SaveInput = ""
+	case "ctrl+c":
+		return m.quit()
+	default:
+		if text := msg.Key().Text; text != "" && unicode.IsPrint([]rune(text)[0]) {
+			m.SaveInput += text
+		}
+	}
+	return nil
+}
+
+// renderSavePrompt draws the save-as prompt in place of the status bar
+func (m model) renderSavePrompt() string {
+	return m.colors().label().Render(fmt.Sprintf(" Save as: %s▏  %s ", m.SaveInput, keyHints(
+		"enter save",
+		"esc cancel",
+	)))
+}
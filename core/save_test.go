@@ -0,0 +1,179 @@
+package jif
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseEditSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    saveSpec
+		wantErr bool
+	}{
+		{spec: "out.gif", want: saveSpec{Path: "out.gif", Options: EditOptions{Optimize: true}}},
+		{
+			spec: "clip.gif range=2:5 crop=10x10+1+2 scale=50% filter=nearest reverse",
+			want: saveSpec{Path: "clip.gif", Options: EditOptions{Range: "2:5", Crop: "10x10+1+2", Scale: "50%", Filter: "nearest", Reverse: true, Optimize: true}},
+		},
+		{
+			spec: "  fast.gif speed=2 delay=40ms loop=1 ",
+			want: saveSpec{Path: "fast.gif", Options: EditOptions{Speed: 2, Delay: 40 * time.Millisecond, Loop: "1", Optimize: true}},
+		},
+		{
+			spec: "small.gif optimize=false colors=16",
+			want: saveSpec{Path: "small.gif", Options: EditOptions{Colors: 16}},
+		},
+		{
+			spec: "small.gif optimize",
+			want: saveSpec{Path: "small.gif", Options: EditOptions{Optimize: true}},
+		},
+		{
+			spec: `"my clips/out.gif" reverse force`,
+			want: saveSpec{Path: "my clips/out.gif", Options: EditOptions{Reverse: true, Optimize: true}, Force: true},
+		},
+		{
+			spec: `'say "hi".gif'`,
+			want: saveSpec{Path: `say "hi".gif`, Options: EditOptions{Optimize: true}},
+		},
+		{spec: "", wantErr: true},
+		{spec: `""`, wantErr: true},
+		{spec: `"out.gif`, wantErr: true},
+		{spec: "out.gif rotate=90", wantErr: true},
+		{spec: "out.gif speed=fast", wantErr: true},
+		{spec: "out.gif colors=many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseEditSpec(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseEditSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseEditSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteSpecWord(t *testing.T) {
+	for _, path := range []string{"out.gif", "my clips/out.gif", `say "hi".gif`} {
+		words, err := splitSpecWords(quoteSpecWord(path) + " reverse")
+		if err != nil || len(words) != 2 || words[0] != path {
+			t.Errorf("quoteSpecWord(%q) splits back into %q, %v", path, words, err)
+		}
+	}
+}
+
+func TestDefaultSavePath(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "anim.gif", want: "anim-edited.gif"},
+		{source: "gifs/cat.GIF", want: "gifs/cat-edited.gif"},
+		{source: "https://example.com/media/dance.gif?x=1", want: "dance-edited.gif"},
+		{source: "https://example.com/", want: "download-edited.gif"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultSavePath(tt.source); got != tt.want {
+			t.Errorf("defaultSavePath(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestSaveAsPrompt(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "clip.gif")
+	m := &model{
+		player:   player{GIF: newEditTestGIF(4), Frames: make([]string, 4)},
+		Ready:    true,
+		Playlist: []string{"anim.gif"},
+	}
+
+	m.Update(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	if !m.Saving || m.SaveInput != "anim-edited.gif" {
+		t.Fatalf("S opened prompt %v with %q, want anim-edited.gif", m.Saving, m.SaveInput)
+	}
+
+	// Keys type into the prompt instead of triggering their actions
+	m.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	for _, r := range out + " range=1:2" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if m.Paused || m.SaveInput != out+" range=1:2" {
+		t.Fatalf("prompt = %q, paused %v", m.SaveInput, m.Paused)
+	}
+
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil || m.Saving {
+		t.Fatal("enter did not start saving")
+	}
+	m.Update(cmd())
+	if !strings.HasPrefix(m.SaveStatus, "Saved "+out+": 2 frames") {
+		t.Errorf("SaveStatus = %q, want Saved %s: 2 frames...", m.SaveStatus, out)
+	}
+
+	g, err := loadGIF(out)
+	if err != nil {
+		t.Fatalf("reading saved GIF: %v", err)
+	}
+	if len(g.Image) != 2 {
+		t.Errorf("saved GIF has %d frames, want 2", len(g.Image))
+	}
+}
+
+func TestSaveAsPromptError(t *testing.T) {
+	m := &model{player: player{GIF: newEditTestGIF(2), Frames: make([]string, 2)}, Ready: true, Playlist: []string{"anim.gif"}}
+
+	m.Update(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	for _, r := range " range=5:9" {
+		m.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m.Update(cmd())
+	if m.SaveStatus == "" || m.SaveStatus[:11] != "Save failed" {
+		t.Errorf("SaveStatus = %q, want a failure", m.SaveStatus)
+	}
+
+	// Reopening keeps the text to correct it; esc closes without saving
+	m.Update(tea.KeyPressMsg{Code: 'S', Text: "S"})
+	if m.SaveInput != "anim-edited.gif range=5:9" {
+		t.Errorf("reopened prompt = %q, want the previous text", m.SaveInput)
+	}
+	if _, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape}); cmd != nil || m.Saving {
+		t.Errorf("esc left the prompt open")
+	}
+}
+
+func TestSaveAsPromptExistingFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "clip.gif")
+	if err := os.WriteFile(out, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := &model{player: player{GIF: newEditTestGIF(2), Frames: make([]string, 2)}, Ready: true, Playlist: []string{"anim.gif"}}
+	m.SaveInput = out
+
+	// An existing file is left alone until force is added
+	if cmd := m.startSave(); cmd != nil || !strings.Contains(m.SaveStatus, "exists") {
+		t.Fatalf("saving over %s: status %q, want a refusal", out, m.SaveStatus)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "keep" {
+		t.Fatal("the existing file was replaced")
+	}
+
+	m.SaveInput += " force"
+	cmd := m.startSave()
+	if cmd == nil {
+		t.Fatalf("force did not start saving: %q", m.SaveStatus)
+	}
+	m.Update(cmd())
+	if g, err := loadGIF(out); err != nil || len(g.Image) != 2 {
+		t.Errorf("force did not replace the file: %v", err)
+	}
+}
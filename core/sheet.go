@@ -0,0 +1,261 @@
+package jif
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// Contact sheet geometry in terminal cells: the blank columns between tiles
+// and the tile width aimed for when the column count is not given
+const (
+	contactGap       = 1
+	contactTileWidth = 20
+)
+
+// SheetOptions configures SpriteSheet and ContactSheet
+type SheetOptions struct {
+	Columns int // frames per row; 0 picks a near-square layout
+	Every   int // keep every Nth frame; 0 or 1 keeps all
+}
+
+// spriteAtlas is the JSON description of a sprite sheet
+type spriteAtlas struct {
+	Image       string        `json:"image"`
+	Source      string        `json:"source"`
+	FrameWidth  int           `json:"frame_width"`
+	FrameHeight int           `json:"frame_height"`
+	Columns     int           `json:"columns"`
+	Rows        int           `json:"rows"`
+	LoopCount   int           `json:"loop_count"`
+	Frames      []spriteFrame `json:"frames"`
+}
+
+// spriteFrame is one frame's cell in the sprite sheet; the duration covers
+// the frames skipped after it so the sampled animation keeps its timing
+type spriteFrame struct {
+	Frame      int   `json:"frame"`
+	X          int   `json:"x"`
+	Y          int   `json:"y"`
+	Width      int   `json:"width"`
+	Height     int   `json:"height"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// ============================================================================
+// Layout
+// ============================================================================
+
+// sampleFrames returns the 0-based frames of n kept when taking every Nth
+func sampleFrames(n, every int) ([]int, error) {
+	if every < 0 {
+		return nil, fmt.Errorf("invalid frame step %d (want a positive number)", every)
+	}
+	every = max(1, every)
+
+	var frames []int
+	for i := 0; i < n; i += every {
+		frames = append(frames, i)
+	}
+	return frames, nil
+}
+
+// sheetColumns returns how many frames go in each row of a sheet of count
+// frames, defaulting to a near-square layout
+func sheetColumns(count, columns int) (int, error) {
+	if columns < 0 {
+		return 0, fmt.Errorf("invalid column count %d (want a positive number)", columns)
+	}
+	if columns == 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(count))))
+	}
+	return max(1, min(columns, count)), nil
+}
+
+// sampledDuration returns how long the sampled frame at position k stands
+// for: its own delay plus those of the frames skipped up to the next sample
+func sampledDuration(g *gif.GIF, frames []int, k int) int64 {
+	end := len(g.Image)
+	if k+1 < len(frames) {
+		end = frames[k+1]
+	}
+
+	var ms int64
+	for i := frames[k]; i < end; i++ {
+		ms += frameDelay(g, i).Milliseconds()
+	}
+	return ms
+}
+
+// sheetFrames is the composited frames kept for a sheet
+type sheetFrames struct {
+	GIF     *gif.GIF
+	Frames  []int // 0-based frame of each image
+	Images  []*image.RGBA
+	Columns int
+}
+
+// loadSheetFrames loads source and composites the frames kept by opts
+func loadSheetFrames(source string, opts SheetOptions) (sheetFrames, error) {
+	g, _, err := loadGIFWithStats(source)
+	if err != nil {
+		return sheetFrames{}, fmt.Errorf("loading GIF: %w", err)
+	}
+	frames, err := sampleFrames(len(g.Image), opts.Every)
+	if err != nil {
+		return sheetFrames{}, err
+	}
+	if len(frames) == 0 {
+		return sheetFrames{}, fmt.Errorf("GIF has no frames")
+	}
+	columns, err := sheetColumns(len(frames), opts.Columns)
+	if err != nil {
+		return sheetFrames{}, err
+	}
+
+	sf := sheetFrames{GIF: g, Frames: frames, Columns: columns}
+	keep := make(map[int]bool, len(frames))
+	for _, i := range frames {
+		keep[i] = true
+	}
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		if keep[i] {
+			sf.Images = append(sf.Images, img)
+		}
+	})
+	return sf, nil
+}
+
+// ============================================================================
+// Sprite Sheet
+// ============================================================================
+
+// atlasPath returns where the atlas for the sprite sheet at out is written:
+// next to it, with a .json extension
+func atlasPath(out string) string {
+	return strings.TrimSuffix(out, filepath.Ext(out)) + ".json"
+}
+
+// SpriteSheet lays the composited frames of source out in rows on a single
+// PNG written to out, and writes a JSON atlas next to it giving each frame's
+// rectangle and duration. It returns the number of frames on the sheet.
+func SpriteSheet(source, out string, opts SheetOptions) (int, error) {
+	sf, err := loadSheetFrames(source, opts)
+	if err != nil {
+		return 0, err
+	}
+	g, frames, columns := sf.GIF, sf.Frames, sf.Columns
+
+	frameWidth, frameHeight := getGifDimensions(g)
+	rows := (len(frames) + columns - 1) / columns
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*frameWidth, rows*frameHeight))
+
+	atlas := spriteAtlas{
+		Image:       filepath.Base(out),
+		Source:      filepath.Base(source),
+		FrameWidth:  frameWidth,
+		FrameHeight: frameHeight,
+		Columns:     columns,
+		Rows:        rows,
+		LoopCount:   g.LoopCount,
+	}
+	for k, img := range sf.Images {
+		rect := image.Rect(0, 0, frameWidth, frameHeight).
+			Add(image.Pt(k%columns*frameWidth, k/columns*frameHeight))
+		draw.Draw(sheet, rect, img, image.Point{}, draw.Src)
+
+		atlas.Frames = append(atlas.Frames, spriteFrame{
+			Frame:      frames[k] + 1,
+			X:          rect.Min.X,
+			Y:          rect.Min.Y,
+			Width:      frameWidth,
+			Height:     frameHeight,
+			DurationMS: sampledDuration(g, frames, k),
+		})
+	}
+
+	if err := writePNG(out, sheet); err != nil {
+		return 0, err
+	}
+	data, err := json.MarshalIndent(atlas, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(atlasPath(out), append(data, '\n'), 0o644); err != nil {
+		return 0, err
+	}
+	return len(frames), nil
+}
+
+// ============================================================================
+// Contact Sheet
+// ============================================================================
+
+// ContactSheet prints the composited frames of source to w as a grid of
+// halfblock tiles, each labelled with its frame number. The grid fills
+// Options.Width columns, or the terminal width when it is 0, with as many
+// tiles about 20 columns wide as fit unless the column count is given.
+func ContactSheet(w io.Writer, source string, sheet SheetOptions, opts Options) error {
+	w, err := outputWriter(w, opts.Color)
+	if err != nil {
+		return err
+	}
+	if opts.Width < 0 {
+		return fmt.Errorf("invalid width %d", opts.Width)
+	}
+
+	width := outputWidth(opts.Width)
+	if sheet.Columns == 0 {
+		sheet.Columns = max(1, (width+contactGap)/(contactTileWidth+contactGap))
+	}
+
+	sf, err := loadSheetFrames(source, sheet)
+	if err != nil {
+		return err
+	}
+	images, columns := sf.Images, sf.Columns
+
+	tileWidth := (width - (columns-1)*contactGap) / columns
+	if tileWidth < 1 {
+		return fmt.Errorf("%d columns do not fit in %d cells", columns, width)
+	}
+
+	tileOpts := opts
+	tileOpts.Width, tileOpts.Height = tileWidth, 0
+	tile, err := catModel(tileOpts, images[0])
+	if err != nil {
+		return err
+	}
+
+	label := lipgloss.NewStyle().Width(tileWidth).AlignHorizontal(lipgloss.Center)
+	gap := strings.Repeat(" ", contactGap)
+
+	var rows []string
+	for start := 0; start < len(images); start += columns {
+		var tiles []string
+		for k := start; k < min(len(images), start+columns); k++ {
+			if k > start {
+				tiles = append(tiles, gap)
+			}
+			tiles = append(tiles, lipgloss.JoinVertical(lipgloss.Center,
+				label.Render(strings.TrimSuffix(tile.renderImageHalfBlock(images[k], nil), "\n")),
+				label.Render(fmt.Sprintf("#%d", sf.Frames[k]+1)),
+			))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, tiles...))
+	}
+
+	if _, err := io.WriteString(w, strings.Join(rows, "\n")+"\n"); err != nil {
+		return fmt.Errorf("writing contact sheet: %w", err)
+	}
+	return nil
+}
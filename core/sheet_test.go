@@ -0,0 +1,157 @@
+package jif
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSampleFrames(t *testing.T) {
+	tests := []struct {
+		n, every int
+		want     []int
+		wantErr  bool
+	}{
+		{n: 4, every: 0, want: []int{0, 1, 2, 3}},
+		{n: 4, every: 1, want: []int{0, 1, 2, 3}},
+		{n: 7, every: 3, want: []int{0, 3, 6}},
+		{n: 2, every: 5, want: []int{0}},
+		{n: 4, every: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := sampleFrames(tt.n, tt.every)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sampleFrames(%d, %d) error = %v, wantErr %v", tt.n, tt.every, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sampleFrames(%d, %d) = %v, want %v", tt.n, tt.every, got, tt.want)
+		}
+	}
+}
+
+func TestSheetColumns(t *testing.T) {
+	tests := []struct {
+		count, columns int
+		want           int
+		wantErr        bool
+	}{
+		{count: 10, columns: 0, want: 4},
+		{count: 9, columns: 0, want: 3},
+		{count: 1, columns: 0, want: 1},
+		{count: 10, columns: 8, want: 8},
+		{count: 3, columns: 8, want: 3},
+		{count: 3, columns: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := sheetColumns(tt.count, tt.columns)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sheetColumns(%d, %d) error = %v, wantErr %v", tt.count, tt.columns, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sheetColumns(%d, %d) = %d, want %d", tt.count, tt.columns, got, tt.want)
+		}
+	}
+}
+
+func TestSampledDuration(t *testing.T) {
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, 5),
+		Delay: []int{10, 20, 30, 40, 50},
+	}
+	frames := []int{0, 2, 4}
+
+	want := []int64{300, 700, 500}
+	for k := range frames {
+		if got := sampledDuration(g, frames, k); got != want[k] {
+			t.Errorf("sampledDuration(%d) = %d, want %d", k, got, want[k])
+		}
+	}
+}
+
+func TestSpriteSheet(t *testing.T) {
+	const source = "../testdata/multi.gif"
+	g, err := loadGIF(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frameWidth, frameHeight := getGifDimensions(g)
+
+	out := filepath.Join(t.TempDir(), "sheet.png")
+	n, err := SpriteSheet(source, out, SheetOptions{Columns: 3, Every: 2})
+	if err != nil {
+		t.Fatalf("SpriteSheet() error = %v", err)
+	}
+	wantFrames := (len(g.Image) + 1) / 2
+	if n != wantFrames {
+		t.Errorf("SpriteSheet() = %d, want %d", n, wantFrames)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sheet, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := (wantFrames + 2) / 3
+	if got, want := sheet.Bounds().Size(), image.Pt(3*frameWidth, rows*frameHeight); got != want {
+		t.Errorf("sheet size = %v, want %v", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(out), "sheet.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var atlas spriteAtlas
+	if err := json.Unmarshal(data, &atlas); err != nil {
+		t.Fatalf("atlas is not valid JSON: %v", err)
+	}
+	if len(atlas.Frames) != wantFrames {
+		t.Fatalf("atlas has %d frames, want %d", len(atlas.Frames), wantFrames)
+	}
+	if got := atlas.Frames[4]; got.Frame != 9 || got.X != frameWidth || got.Y != frameHeight {
+		t.Errorf("atlas frame 5 = %+v, want frame 9 at %d,%d", got, frameWidth, frameHeight)
+	}
+
+	var total int64
+	for _, frame := range atlas.Frames {
+		total += frame.DurationMS
+	}
+	if want := totalDuration(g).Milliseconds(); total != want {
+		t.Errorf("atlas durations sum to %dms, want %dms", total, want)
+	}
+}
+
+func TestContactSheet(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Width: 50, CellAspect: 2}
+	if err := ContactSheet(&buf, "../testdata/simple.gif", SheetOptions{}, opts); err != nil {
+		t.Fatalf("ContactSheet() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(ansi.Strip(buf.String()), "\n"), "\n")
+	for i, line := range lines {
+		if w := ansi.StringWidth(line); w > opts.Width {
+			t.Errorf("line %d is %d cells wide, want at most %d", i, w, opts.Width)
+		}
+	}
+	labels := strings.Fields(lines[len(lines)-1])
+	if !slices.Equal(labels, []string{"#1", "#2"}) {
+		t.Errorf("ContactSheet() labels = %v, want [#1 #2]", labels)
+	}
+}
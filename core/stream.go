@@ -0,0 +1,155 @@
+package jif
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// ============================================================================
+// Output
+// ============================================================================
+
+// outputWriter wraps w to downsample colours to the forced colour mode or,
+// when w is a terminal, to what the terminal supports. Redirected output is
+// written in truecolor unless a mode is forced.
+func outputWriter(w io.Writer, colorMode string) (io.Writer, error) {
+	profile, forceProfile, err := parseColorMode(colorMode)
+	if err != nil {
+		return nil, err
+	}
+	if forceProfile {
+		return &colorprofile.Writer{Forward: w, Profile: profile}, nil
+	}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(f.Fd()) {
+		return &colorprofile.Writer{Forward: w, Profile: colorprofile.Detect(f, os.Environ())}, nil
+	}
+	return w, nil
+}
+
+// ============================================================================
+// Streaming
+// ============================================================================
+
+// renderAllFrames renders every frame of g for streaming, sized like Cat,
+// giving up with ctx.Err() once ctx is done
+func renderAllFrames(ctx context.Context, g *gif.GIF, opts Options) ([]string, error) {
+	var (
+		frames []string
+		m      model
+		err    error
+	)
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		if err != nil || ctx.Err() != nil {
+			return
+		}
+		if i == 0 {
+			if m, err = catModel(opts, img); err != nil {
+				return
+			}
+		}
+		frames = append(frames, m.renderImageHalfBlock(img, nil))
+	})
+
+	switch {
+	case err != nil:
+		return nil, err
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case len(frames) == 0:
+		return nil, fmt.Errorf("GIF has no frames")
+	}
+	return frames, nil
+}
+
+// streamFrames draws frames to w in place, moving the cursor back up before
+// each redraw, until the play limit is reached or ctx is done
+func streamFrames(ctx context.Context, w io.Writer, g *gif.GIF, frames []string, loop string, speed float64) error {
+	limit := playLimit(loop, g)
+	rows := strings.Count(frames[0], "\n")
+
+	for plays, first := 0, true; limit == 0 || plays < limit; plays++ {
+		for i, frame := range frames {
+			out := frame
+			if !first {
+				out = ansi.CursorUp(rows) + "\r" + frame
+			}
+			first = false
+
+			if _, err := io.WriteString(w, out); err != nil {
+				return fmt.Errorf("writing frame: %w", err)
+			}
+
+			// The last frame of the last play is left on screen
+			if limit > 0 && plays == limit-1 && i == len(frames)-1 {
+				return nil
+			}
+
+			timer := time.NewTimer(playbackDelay(g, i, speed))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+		}
+	}
+	return nil
+}
+
+// Stream plays each source to w without the interactive viewer, redrawing
+// in place with cursor movement and honouring the frame delays, Options.Speed
+// and Options.Loop. It stops early, restoring the cursor, when ctx is done.
+func Stream(ctx context.Context, w io.Writer, args []string, opts Options) error {
+	speed, err := parseSpeed(opts.Speed)
+	if err != nil {
+		return err
+	}
+	loop, err := parseLoop(opts.Loop)
+	if err != nil {
+		return err
+	}
+	w, err = outputWriter(w, opts.Color)
+	if err != nil {
+		return err
+	}
+
+	sources, err := expandSources(args)
+	if err != nil {
+		return err
+	}
+
+	io.WriteString(w, ansi.HideCursor)
+	defer io.WriteString(w, ansi.ShowCursor)
+
+	for _, source := range sources {
+		g, _, err := loadGIFWithStats(source)
+		if err != nil {
+			return fmt.Errorf("loading GIF: %w", err)
+		}
+
+		frames, err := renderAllFrames(ctx, g, opts)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := streamFrames(ctx, w, g, frames, loop, speed); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package jif
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStreamFrames(t *testing.T) {
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, 2),
+		Delay: []int{1, 1},
+	}
+	frames := []string{"a1\na2\n", "b1\nb2\n"}
+	up := ansi.CursorUp(2) + "\r"
+
+	tests := []struct {
+		name string
+		loop string
+		want string
+	}{
+		{
+			name: "one play",
+			loop: "1",
+			want: "a1\na2\n" + up + "b1\nb2\n",
+		},
+		{
+			name: "a GIF looping forever plays once by default",
+			loop: LoopFinite,
+			want: "a1\na2\n" + up + "b1\nb2\n",
+		},
+		{
+			name: "two plays redraw in place",
+			loop: "2",
+			want: "a1\na2\n" + up + "b1\nb2\n" + up + "a1\na2\n" + up + "b1\nb2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := streamFrames(context.Background(), &buf, g, frames, tt.loop, 4); err != nil {
+				t.Fatalf("streamFrames() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("streamFrames() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	// Cancelling stops a GIF that would loop forever
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	if err := streamFrames(ctx, &buf, g, frames, loopForever, 1); err != nil {
+		t.Fatalf("streamFrames() error = %v", err)
+	}
+	if buf.String() != frames[0] {
+		t.Errorf("cancelled streamFrames() = %q, want only the first frame", buf.String())
+	}
+}
+
+func TestStream(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Width: 10, Height: 3, CellAspect: 2, Loop: "1", Speed: 16}
+	if err := Stream(context.Background(), &buf, []string{"../testdata/multi.gif"}, opts); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, ansi.HideCursor) || !strings.HasSuffix(out, ansi.ShowCursor) {
+		t.Error("Stream() did not hide and restore the cursor")
+	}
+	g, _, err := loadGIFWithStats("../testdata/multi.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out, ansi.CursorUp(3)); n != len(g.Image)-1 {
+		t.Errorf("Stream() redrew %d times, want one per frame after the first (%d)", n, len(g.Image)-1)
+	}
+}
+
+func TestOutputWriter(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "frame.ans"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	tests := []struct {
+		name string
+		w    io.Writer
+		mode string
+		wrap bool
+		want colorprofile.Profile
+	}{
+		{"buffer", &bytes.Buffer{}, "", false, 0},
+		{"redirected to a file", file, "auto", false, 0},
+		{"forced mode", &bytes.Buffer{}, "256", true, colorprofile.ANSI256},
+		{"forced mode to a file", file, "16", true, colorprofile.ANSI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := outputWriter(tt.w, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cw, ok := w.(*colorprofile.Writer)
+			if ok != tt.wrap {
+				t.Fatalf("outputWriter() = %T, want wrapped %v", w, tt.wrap)
+			}
+			if ok && cw.Profile != tt.want {
+				t.Errorf("profile = %v, want %v", cw.Profile, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamCancelledWhileRendering(t *testing.T) {
+	g, _, err := loadGIFWithStats("../testdata/multi.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts := Options{Width: 10, Height: 3, CellAspect: 2}
+	if frames, err := renderAllFrames(ctx, g, opts); !errors.Is(err, context.Canceled) || frames != nil {
+		t.Errorf("renderAllFrames() = %d frames, %v, want context.Canceled", len(frames), err)
+	}
+
+	// Stream stops quietly, restoring the cursor without drawing a frame
+	var buf bytes.Buffer
+	if err := Stream(ctx, &buf, []string{"../testdata/multi.gif"}, opts); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if buf.String() != ansi.HideCursor+ansi.ShowCursor {
+		t.Errorf("Stream() wrote %q after being cancelled", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package jif
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+// theme holds the colours of the UI drawn around the GIF
+type theme struct {
+	// Accent is used for titles, panel borders, selections and the cursor
+	Accent color.Color
+
+	// Muted is used for labels, the status bar and inactive borders
+	Muted color.Color
+
+	// Loading is used for the loading progress and messages
+	Loading color.Color
+}
+
+// themeAuto picks dark or light from the terminal background
+const themeAuto = "auto"
+
+var themes = map[string]theme{
+	"dark": {
+		Accent:  lipgloss.Color("213"),
+		Muted:   lipgloss.Color("240"),
+		Loading: lipgloss.Color("86"),
+	},
+	"light": {
+		Accent:  lipgloss.Color("127"),
+		Muted:   lipgloss.Color("244"),
+		Loading: lipgloss.Color("30"),
+	},
+	"high-contrast": {
+		Accent:  lipgloss.Color("11"),
+		Muted:   lipgloss.Color("15"),
+		Loading: lipgloss.Color("14"),
+	},
+	"high-contrast-light": {
+		Accent:  lipgloss.Color("4"),
+		Muted:   lipgloss.Color("0"),
+		Loading: lipgloss.Color("6"),
+	},
+}
+
+// themeNames lists the themes in the order shown in errors and help
+var themeNames = []string{themeAuto, "dark", "light", "high-contrast", "high-contrast-light"}
+
+// defaultTheme backs models created without a theme
+var defaultTheme = themes["dark"]
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+// loadTheme returns the named theme with colours overridden by name (accent,
+// muted or loading); auto starts dark until the terminal reports its
+// background
+func loadTheme(name string, colors map[string]string) (*theme, error) {
+	name = strings.ToLower(name)
+	if name == "" || name == themeAuto {
+		name = "dark"
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(themeNames, ", "))
+	}
+	if err := t.override(colors); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// override replaces colours by name; values are ANSI numbers or hex codes
+func (t *theme) override(colors map[string]string) error {
+	fields := map[string]*color.Color{
+		"accent":  &t.Accent,
+		"muted":   &t.Muted,
+		"loading": &t.Loading,
+	}
+
+	// Sorted so that the reported error does not depend on map order
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown theme colour %q (want accent, muted or loading)", name)
+		}
+		c := lipgloss.Color(colors[name])
+		if _, invalid := c.(lipgloss.NoColor); invalid {
+			return fmt.Errorf("invalid %s colour %q (want an ANSI number or #rrggbb)", name, colors[name])
+		}
+		*field = c
+	}
+	return nil
+}
+
+// handleBackgroundColor records the terminal background, which partly
+// transparent pixels are blended against, and switches an automatic theme
+// to suit it
+func (m *model) handleBackgroundColor(msg tea.BackgroundColorMsg) (tea.Model, tea.Cmd) {
+	// Frames being rendered keep blending against the old background until
+	// they are rendered again
+	var cmd tea.Cmd
+	switch {
+	case m.Loading:
+		m.pendingBackground = msg.Color
+		m.reprocessPending = m.reprocessPending || m.Background.Mode == backgroundTerminal
+	default:
+		m.TerminalBackground = msg.Color
+		if m.Background.Mode == backgroundTerminal && m.Ready && m.GIF != nil {
+			cmd = m.reprocess()
+		}
+	}
+	if !m.autoTheme {
+		return m, cmd
+	}
+
+	name := "dark"
+	if !msg.IsDark() {
+		name = "light"
+	}
+	if t, err := loadTheme(name, m.themeColors); err == nil {
+		m.theme = t
+	}
+	return m, cmd
+}
+
+// colors returns the theme in use
+func (m model) colors() theme {
+	if m.theme != nil {
+		return *m.theme
+	}
+	return defaultTheme
+}
+
+// ============================================================================
+// Styles
+// ============================================================================
+
+// title styles panel headings
+func (t theme) title() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+}
+
+// label styles secondary text
+func (t theme) label() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Muted)
+}
+
+// highlight styles selected or flagged items
+func (t theme) highlight() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Accent)
+}
+
+// panel styles the bordered panels drawn over the GIF
+func (t theme) panel() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(0, 1)
+}
@@ -0,0 +1,87 @@
+package jif
+
+import (
+	"image/color"
+	"image/gif"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	lipgloss "charm.land/lipgloss/v2"
+)
+
+func TestLoadTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		colors  map[string]string
+		want    theme
+		wantErr bool
+	}{
+		{name: "", want: themes["dark"]},
+		{name: "auto", want: themes["dark"]},
+		{name: "Light", want: themes["light"]},
+		{name: "high-contrast", want: themes["high-contrast"]},
+		{
+			name:   "dark",
+			colors: map[string]string{"accent": "#ff0000", "muted": "8"},
+			want:   theme{Accent: lipgloss.Color("#ff0000"), Muted: lipgloss.Color("8"), Loading: themes["dark"].Loading},
+		},
+		{name: "solarized", wantErr: true},
+		{name: "dark", colors: map[string]string{"border": "1"}, wantErr: true},
+		{name: "dark", colors: map[string]string{"accent": "pink"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := loadTheme(tt.name, tt.colors)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("loadTheme(%q, %v) error = %v, wantErr %v", tt.name, tt.colors, err, tt.wantErr)
+			continue
+		}
+		if err == nil && *got != tt.want {
+			t.Errorf("loadTheme(%q, %v) = %+v, want %+v", tt.name, tt.colors, *got, tt.want)
+		}
+	}
+}
+
+func TestBackgroundColorSwitchesAutoTheme(t *testing.T) {
+	white := tea.BackgroundColorMsg{Color: color.White}
+	colors := map[string]string{"loading": "1"}
+
+	m := &model{autoTheme: true, themeColors: colors}
+	m.Update(white)
+	if m.colors().Accent != themes["light"].Accent {
+		t.Errorf("auto theme on a white background: accent = %v, want light", m.colors().Accent)
+	}
+	if m.colors().Loading != lipgloss.Color("1") {
+		t.Errorf("auto theme lost the loading override: %v", m.colors().Loading)
+	}
+
+	m.Update(tea.BackgroundColorMsg{Color: color.Black})
+	if m.colors().Accent != themes["dark"].Accent {
+		t.Errorf("auto theme on a black background: accent = %v, want dark", m.colors().Accent)
+	}
+
+	// A named theme ignores the terminal
+	hc := themes["high-contrast"]
+	m = &model{theme: &hc}
+	m.Update(white)
+	if m.colors() != hc {
+		t.Errorf("named theme changed on background report: %+v", m.colors())
+	}
+}
+
+func TestBackgroundColorWhileLoading(t *testing.T) {
+	m := &model{
+		player:     player{GIF: &gif.GIF{Delay: []int{10}}, Frames: []string{"frame1"}, Loading: true},
+		Background: background{Mode: backgroundTerminal},
+	}
+
+	// The first render is still blending against the unknown background
+	if _, cmd := m.Update(tea.BackgroundColorMsg{Color: color.White}); cmd != nil || m.TerminalBackground != nil {
+		t.Fatalf("TerminalBackground = %v while rendering, want it kept until the render ends", m.TerminalBackground)
+	}
+
+	_, cmd := m.Update(processingCompleteMsg{})
+	if m.TerminalBackground != color.White || cmd == nil || !m.Loading {
+		t.Errorf("after the render: background %v, loading %v, want white and a new render", m.TerminalBackground, m.Loading)
+	}
+}
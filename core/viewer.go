@@ -12,8 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
+	uv "github.com/charmbracelet/ultraviolet"
 	"github.com/nfnt/resize"
 )
 
@@ -34,24 +36,114 @@ type progressMsg struct {
 // ============================================================================
 
 type model struct {
-	// GIF data
-	GIF          *gif.GIF
-	Frames       []string
-	CurrentFrame int
+	// The GIF shown full-screen; the grid gallery runs one player per tile
+	player
 
 	// Display state
-	Width    int
-	Height   int
-	Paused   bool
-	ShowHelp bool
-	Ready    bool
+	Width       int
+	Height      int
+	Paused      bool
+	ShowHelp    bool
+	ShowInfo    bool
+	ShowPalette bool
+	Ready       bool
+	PixelInfo   string
+
+	// Playback settings; Plays counts completed passes for the loop limit
+	Speed float64
+	Loop  string
+	Plays int
+
+	// Pixel inspector state, Cursor is in source pixels
+	Inspecting bool
+	Cursor     image.Point
+
+	// Playlist state
+	Playlist      []string
+	PlaylistIndex int
+	LoadError     string
+	pendingSource string
+	preloaded     map[string]loadedGIF
+	preloading    map[string]bool
+
+	// A file picked while frames are rendering, shown once they finish
+	queuedFile int
+	fileQueued bool
+
+	// Filmstrip state
+	ShowFilmstrip bool
+	Thumbnails    map[int]string
+
+	// Grid gallery state
+	ShowGrid     bool
+	Grid         []*player
+	GridSelected int
+	GridOffset   int
+	gridGen      int
+
+	// The tiles last on screen; moving the window starts a new gridGen so
+	// that tiles scrolled away stop ticking
+	gridStart, gridEnd int
+
+	// File browser state, nil unless started on a directory
+	Browser     *browser
+	ShowBrowser bool
+
+	// Zoom & pan state
+	Fit           fitMode
+	Zoom          int
+	PanX          int
+	PanY          int
+	ViewportFrame string
+	Overlay       overlayMode
+	OverlayInfo   string
+	diffs         []*diffFrame
+
+	// Save-as prompt: SaveInput is the text being typed and SaveStatus
+	// reports the last save until the next key press
+	Saving     bool
+	SaveInput  string
+	SaveStatus string
+
+	// Inline playback draws in the scrollback at InlineHeight rows and
+	// leaves ExitFrame, or the frame on screen, printed once quitting
+	Inline       bool
+	InlineHeight int
+	ExitFrame    int
+	quitting     bool
+
+	// Transparency backdrop; TerminalBackground is nil until the terminal
+	// reports it
+	Background         background
+	TerminalBackground color.Color
+	drag               dragState
+
+	// Cell geometry
+	CellAspect      float64
+	PixelChars      int
+	cellAspectFixed bool
+
+	// A cell size or terminal background reported, or a resize, while
+	// frames are rendering; the frames are rendered again once the current
+	// render completes
+	pendingCellAspect float64
+	pendingBackground color.Color
+	reprocessPending  bool
 
 	// Progressive loading state
-	Loading      bool
 	LoadingFrame string
 	LoadingRows  int
 	TotalRows    int
 
+	// Key bindings, nil uses the default keymap
+	keys *keyMap
+
+	// UI colours, nil uses the dark theme; an automatic theme is replaced
+	// once the terminal reports its background, keeping themeColors
+	theme       *theme
+	autoTheme   bool
+	themeColors map[string]string
+
 	// Reference to program for sending messages
 	program *tea.Program
 }
@@ -60,26 +152,27 @@ type model struct {
 // Rendering
 // ============================================================================
 
-// renderHalfBlockChar converts two vertically stacked pixels into a halfblock character
-func renderHalfBlockChar(topColor, bottomColor color.Color) string {
+// renderHalfBlockChar converts two vertically stacked pixels into a halfblock
+// character repeated chars times
+func renderHalfBlockChar(topColor, bottomColor color.Color, chars int) string {
 	topR, topG, topB, topA := topColor.RGBA()
 	bottomR, bottomG, bottomB, bottomA := bottomColor.RGBA()
 
 	// Both transparent - render nothing
 	if topA == 0 && bottomA == 0 {
-		return "  "
+		return strings.Repeat(" ", chars)
 	}
 
 	// Only bottom pixel visible
 	if topA == 0 {
 		hex := fmt.Sprintf("#%02x%02x%02x", uint8(bottomR>>8), uint8(bottomG>>8), uint8(bottomB>>8))
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(hex)).Render("▄▄")
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(hex)).Render(strings.Repeat("▄", chars))
 	}
 
 	// Only top pixel visible
 	if bottomA == 0 {
 		hex := fmt.Sprintf("#%02x%02x%02x", uint8(topR>>8), uint8(topG>>8), uint8(topB>>8))
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(hex)).Render("▀▀")
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(hex)).Render(strings.Repeat("▀", chars))
 	}
 
 	// Both pixels visible - use foreground and background colors
@@ -88,29 +181,52 @@ func renderHalfBlockChar(topColor, bottomColor color.Color) string {
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color(topHex)).
 		Background(lipgloss.Color(bottomHex)).
-		Render("▀▀")
+		Render(strings.Repeat("▀", chars))
 }
 
 // calculateImageSize determines the target size for the image within terminal bounds
 func (m *model) calculateImageSize(img image.Image) (width, height int) {
-	// Each terminal cell is 2 characters wide (we render ▀▀ or ▄▄)
-	maxWidth := m.Width / 2
+	switch m.Fit {
+	case fitStretch, fitCover:
+		// Fill the terminal; cover crops the source to match beforehand
+		return m.Width / m.charsPerPixel(), m.imageHeight() * 2
+	case fitOriginal:
+		return img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	// Each pixel column is one or two characters wide (▀ or ▀▀) and pixel rows
+	// are stretched to compensate for the cell aspect ratio
+	maxWidth := m.Width / m.charsPerPixel()
 	ratio := float64(img.Bounds().Dy()) / float64(img.Bounds().Dx())
-	targetHeight := int(float64(maxWidth) * ratio * 2)
+	stretch := m.pixelStretch()
+	targetHeight := int(float64(maxWidth) * ratio * stretch)
 
 	// If height exceeds terminal, scale down
-	if targetHeight > m.Height*2 {
-		targetHeight = m.Height * 2
-		maxWidth = int(float64(targetHeight) / ratio / 2)
+	if targetHeight > m.imageHeight()*2 {
+		targetHeight = m.imageHeight() * 2
+		maxWidth = int(float64(targetHeight) / ratio / stretch)
 	}
 
 	return maxWidth, targetHeight
 }
 
+// scaleImage fits img to the render size and puts it over the background,
+// giving the pixels that are drawn two per cell
+func (m *model) scaleImage(img image.Image) image.Image {
+	width, height := m.calculateImageSize(img)
+	src := subImage(img, m.fitSource(img.Bounds()))
+	resized := resize.Resize(uint(width), uint(height), src, resize.Lanczos3)
+	return m.Background.flatten(resized, m.TerminalBackground)
+}
+
 // renderImageHalfBlock converts an image to halfblock characters with optional progressive updates
 func (m *model) renderImageHalfBlock(img image.Image, progressChan chan<- progressMsg) string {
-	width, height := m.calculateImageSize(img)
-	resized := resize.Resize(uint(width), uint(height), img, resize.Lanczos3)
+	return renderHalfBlocks(m.scaleImage(img), m.charsPerPixel(), progressChan)
+}
+
+// renderHalfBlocks renders an already scaled image, two pixel rows per line
+// and chars characters per pixel column
+func renderHalfBlocks(resized image.Image, chars int, progressChan chan<- progressMsg) string {
 	bounds := resized.Bounds()
 
 	var sb strings.Builder
@@ -128,7 +244,7 @@ func (m *model) renderImageHalfBlock(img image.Image, progressChan chan<- progre
 				bottomColor = color.Transparent
 			}
 
-			sb.WriteString(renderHalfBlockChar(topColor, bottomColor))
+			sb.WriteString(renderHalfBlockChar(topColor, bottomColor, chars))
 		}
 		sb.WriteString("\n")
 		currentRow++
@@ -182,14 +298,66 @@ func processFrame(currentImg, previousImg *image.RGBA, srcImg *image.Paletted, d
 	}
 }
 
-// ProcessGIF renders all frames with progressive loading for the first frame
+// compositeFrames applies each frame's disposal method and calls fn with a
+// copy of the full canvas for every frame in order
+func compositeFrames(g *gif.GIF, fn func(i int, img *image.RGBA)) {
+	imgWidth, imgHeight := getGifDimensions(g)
+
+	currentImage := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
+	previousImage := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
+
+	for i, srcImg := range g.Image {
+		// Apply disposal method from previous frame
+		if i > 0 && i-1 < len(g.Disposal) {
+			processFrame(currentImage, previousImage, g.Image[i-1], g.Disposal[i-1])
+		}
+
+		// Save the canvas this frame is drawn over if it is to be restored
+		if i < len(g.Disposal) && g.Disposal[i] == gif.DisposalPrevious {
+			draw.Draw(previousImage, previousImage.Bounds(), currentImage, image.Point{}, draw.Src)
+		}
+
+		// Composite current frame
+		draw.Draw(currentImage, currentImage.Bounds(), srcImg, image.Point{}, draw.Over)
+
+		// Create a copy for rendering
+		imgCopy := image.NewRGBA(currentImage.Bounds())
+		draw.Draw(imgCopy, imgCopy.Bounds(), currentImage, image.Point{}, draw.Src)
+		fn(i, imgCopy)
+	}
+}
+
+// renderFrames composites every frame of g and renders it for the current
+// view, sending progress for the first frame to progress when it is not nil.
+// At original size every frame is drawn through the viewport, so only the
+// composited canvases are produced.
+func (m *model) renderFrames(g *gif.GIF, progress chan<- progressMsg) ([]string, []*image.RGBA) {
+	frames := make([]string, len(g.Image))
+	composited := make([]*image.RGBA, len(g.Image))
+
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		composited[i] = img
+		if m.Fit == fitOriginal {
+			return
+		}
+
+		// Render with progressive updates only for first frame
+		if i == 0 {
+			frames[i] = m.renderImageHalfBlock(img, progress)
+		} else {
+			frames[i] = m.renderImageHalfBlock(img, nil)
+		}
+	})
+	return frames, composited
+}
+
+// ProcessGIF renders all frames with progressive loading for the first
+// frame. The view settings are copied up front, so changes made while the
+// frames render wait for handleProcessingComplete.
 func (m *model) ProcessGIF(p *tea.Program) tea.Cmd {
+	r, g := m.renderer(), m.GIF
 	return func() tea.Msg {
-		imgWidth, imgHeight := getGifDimensions(m.GIF)
-		frames := make([]string, len(m.GIF.Image))
-
-		currentImage := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
-		previousImage := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
+		start := time.Now()
 
 		// Set up progressive loading for first frame
 		progressChan := make(chan progressMsg, 100)
@@ -199,35 +367,12 @@ func (m *model) ProcessGIF(p *tea.Program) tea.Cmd {
 			}
 		}()
 
-		// Process each frame
-		for i, srcImg := range m.GIF.Image {
-			// Save previous state if needed
-			if i > 0 && m.GIF.Disposal[i-1] == gif.DisposalPrevious {
-				draw.Draw(previousImage, previousImage.Bounds(), currentImage, image.Point{}, draw.Src)
-			}
-
-			// Apply disposal method from previous frame
-			if i > 0 {
-				processFrame(currentImage, previousImage, m.GIF.Image[i-1], m.GIF.Disposal[i-1])
-			}
-
-			// Composite current frame
-			draw.Draw(currentImage, currentImage.Bounds(), srcImg, image.Point{}, draw.Over)
-
-			// Create a copy for rendering
-			imgCopy := image.NewRGBA(currentImage.Bounds())
-			draw.Draw(imgCopy, imgCopy.Bounds(), currentImage, image.Point{}, draw.Src)
-
-			// Render with progressive updates only for first frame
-			if i == 0 {
-				frames[i] = m.renderImageHalfBlock(imgCopy, progressChan)
-				close(progressChan)
-			} else {
-				frames[i] = m.renderImageHalfBlock(imgCopy, nil)
-			}
-		}
+		frames, composited := r.renderFrames(g, progressChan)
+		close(progressChan)
 
 		m.Frames = frames
+		m.Composited = composited
+		m.RenderTime = time.Since(start)
 		return processingCompleteMsg{}
 	}
 }
@@ -237,15 +382,50 @@ func (m *model) ProcessGIF(p *tea.Program) tea.Cmd {
 // ============================================================================
 
 func (m *model) Init() tea.Cmd {
-	m.Loading = true
-	return m.ProcessGIF(m.program)
+	cmds := []tea.Cmd{requestCellSize(), tea.RequestBackgroundColor}
+	if m.GIF != nil {
+		m.Loading = true
+		cmds = append(cmds, m.ProcessGIF(m.program))
+	}
+	if m.ShowGrid {
+		m.ShowGrid = false
+		cmds = append(cmds, m.toggleGrid())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.ShowBrowser {
+			return m.handleBrowserKey(msg)
+		}
+		if m.ShowGrid {
+			return m.handleGridKey(msg)
+		}
 		return m.handleKeyPress(msg)
 
+	case tea.MouseClickMsg:
+		if m.ShowBrowser {
+			return m.handleBrowserClick(msg)
+		}
+		if m.ShowGrid {
+			return m.handleGridClick(msg)
+		}
+		return m.handleMouseClick(msg)
+
+	case tea.MouseReleaseMsg:
+		return m.handleMouseRelease(msg)
+
+	case tea.MouseMotionMsg:
+		return m.handleMouseMotion(msg)
+
+	case tea.MouseWheelMsg:
+		if m.ShowBrowser {
+			return m.handleBrowserWheel(msg)
+		}
+		return m.handleMouseWheel(msg)
+
 	case frameMsg:
 		return m.handleFrameAdvance()
 
@@ -255,8 +435,32 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case processingCompleteMsg:
 		return m.handleProcessingComplete()
 
+	case fileLoadedMsg:
+		return m.handleFileLoaded(msg)
+
+	case tileLoadedMsg:
+		return m.handleTileLoaded(msg)
+
+	case gridTickMsg:
+		return m.handleGridTick(msg)
+
+	case previewLoadedMsg:
+		return m.handlePreviewLoaded(msg)
+
+	case previewTickMsg:
+		return m.handlePreviewTick(msg)
+
+	case savedMsg:
+		return m.handleSaved(msg)
+
 	case tea.WindowSizeMsg:
 		return m.handleWindowResize(msg)
+
+	case tea.BackgroundColorMsg:
+		return m.handleBackgroundColor(msg)
+
+	case uv.CellSizeEvent:
+		return m.handleCellSize(msg)
 	}
 
 	return m, nil
@@ -267,38 +471,223 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // ============================================================================
 
 func (m *model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "space":
-		m.Paused = !m.Paused
-		if !m.Paused && m.Ready {
-			return m, m.nextFrame()
-		}
+	keys := m.keyMap()
+
+	if m.Saving {
+		return m, m.handleSaveKey(msg)
+	}
+	m.SaveStatus = ""
+
+	if m.Inspecting && m.handleInspectorKey(msg) {
+		return m, nil
+	}
 
-	case "?":
+	switch {
+	case key.Matches(msg, keys.Pause):
+		return m, m.togglePause()
+
+	case key.Matches(msg, keys.Help):
 		m.ShowHelp = !m.ShowHelp
 
-	case "n", "right":
-		if len(m.Frames) > 0 {
-			m.Paused = true
-			m.CurrentFrame = (m.CurrentFrame + 1) % len(m.Frames)
+	case key.Matches(msg, keys.Info):
+		m.ShowInfo = !m.ShowInfo
+
+	case key.Matches(msg, keys.Inspector):
+		m.toggleInspector()
+
+	case key.Matches(msg, keys.Palette):
+		m.ShowPalette = !m.ShowPalette
+
+	case key.Matches(msg, keys.Overlay):
+		m.cycleOverlay()
+
+	case key.Matches(msg, keys.SaveAs):
+		m.openSavePrompt()
+
+	case key.Matches(msg, keys.Filmstrip):
+		if m.Ready {
+			m.toggleFilmstrip()
+			return m, m.reprocess()
 		}
 
-	case "p", "left":
-		if len(m.Frames) > 0 {
-			m.Paused = true
-			m.CurrentFrame = (m.CurrentFrame - 1 + len(m.Frames)) % len(m.Frames)
+	case key.Matches(msg, keys.NextFrame):
+		m.stepFrame(1)
+
+	case key.Matches(msg, keys.PrevFrame):
+		m.stepFrame(-1)
+
+	case key.Matches(msg, keys.Left):
+		m.handleDirection(-1, 0)
+
+	case key.Matches(msg, keys.Right):
+		m.handleDirection(1, 0)
+
+	case key.Matches(msg, keys.Up):
+		m.handleDirection(0, -1)
+
+	case key.Matches(msg, keys.Down):
+		m.handleDirection(0, 1)
+
+	case key.Matches(msg, keys.ZoomIn):
+		m.zoomIn()
+
+	case key.Matches(msg, keys.ZoomOut):
+		m.zoomOut()
+
+	case key.Matches(msg, keys.ResetZoom):
+		m.setZoom(0)
+
+	case key.Matches(msg, keys.Fit):
+		if m.Ready {
+			m.cycleFit()
+			return m, m.reprocess()
+		}
+
+	case key.Matches(msg, keys.Background):
+		if m.Ready {
+			return m, m.cycleBackground()
 		}
 
-	case "q", "ctrl+c":
-		return m, tea.Quit
+	case key.Matches(msg, keys.NextFile):
+		return m, m.switchFile(1)
+
+	case key.Matches(msg, keys.PrevFile):
+		return m, m.switchFile(-1)
+
+	case key.Matches(msg, keys.Grid):
+		return m, m.toggleGrid()
+
+	case key.Matches(msg, keys.Quit):
+		return m, m.quitOrBrowse()
+
+	case key.Matches(msg, keys.ForceQuit):
+		return m, m.quit()
 	}
 
 	return m, nil
 }
 
+func (m *model) handleMouseClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseLeft:
+		if i, ok := m.filmstripFrameAt(msg.X, msg.Y); ok {
+			m.jumpToFrame(i)
+			return m, nil
+		}
+		if m.Inspecting {
+			if pt, ok := m.cellToSource(msg.X, msg.Y); ok {
+				m.Cursor = pt
+			}
+			return m, nil
+		}
+		m.drag = dragState{
+			active: true,
+			origin: image.Pt(msg.X, msg.Y),
+			pan:    image.Pt(m.PanX, m.PanY),
+		}
+
+	case tea.MouseRight:
+		m.PixelInfo = m.describePixel(msg.X, msg.Y)
+	}
+
+	return m, nil
+}
+
+func (m *model) handleMouseRelease(msg tea.MouseReleaseMsg) (tea.Model, tea.Cmd) {
+	drag := m.drag
+	m.drag = dragState{}
+
+	// A click without movement toggles playback, a drag only pans
+	if drag.active && !drag.moved {
+		return m, m.togglePause()
+	}
+	return m, nil
+}
+
+func (m *model) handleMouseMotion(msg tea.MouseMotionMsg) (tea.Model, tea.Cmd) {
+	if !m.drag.active || msg.Button != tea.MouseLeft {
+		return m, nil
+	}
+
+	dx, dy := msg.X-m.drag.origin.X, msg.Y-m.drag.origin.Y
+	if dx == 0 && dy == 0 {
+		return m, nil
+	}
+	m.drag.moved = true
+
+	if scale := m.scale(); scale > 0 {
+		m.PanX = m.drag.pan.X - dx/(m.charsPerPixel()*scale)
+		m.PanY = m.drag.pan.Y - int(float64(dy*2)/(float64(scale)*m.pixelStretch()))
+		m.pan(0, 0)
+	}
+	return m, nil
+}
+
+func (m *model) handleMouseWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
+	zoom := msg.Mod.Contains(tea.ModCtrl)
+
+	switch msg.Button {
+	case tea.MouseWheelUp:
+		if zoom {
+			m.zoomIn()
+		} else {
+			m.stepFrame(-1)
+		}
+	case tea.MouseWheelDown:
+		if zoom {
+			m.zoomOut()
+		} else {
+			m.stepFrame(1)
+		}
+	}
+
+	return m, nil
+}
+
+// togglePause flips playback and schedules the next frame when resuming
+func (m *model) togglePause() tea.Cmd {
+	// Resuming after the last play starts over
+	if m.Paused && m.finished() {
+		m.Plays = 0
+		m.CurrentFrame = 0
+		m.refreshViewport()
+		m.refreshFilmstrip()
+	}
+
+	m.Paused = !m.Paused
+	if !m.Paused && m.Ready {
+		return m.nextFrame()
+	}
+	return nil
+}
+
+// stepFrame pauses playback and moves delta frames forward or backward
+func (m *model) stepFrame(delta int) {
+	if len(m.Frames) == 0 {
+		return
+	}
+
+	m.Paused = true
+	m.CurrentFrame = ((m.CurrentFrame+delta)%len(m.Frames) + len(m.Frames)) % len(m.Frames)
+	m.refreshViewport()
+	m.refreshFilmstrip()
+}
+
 func (m *model) handleFrameAdvance() (tea.Model, tea.Cmd) {
 	if !m.Paused && m.Ready && len(m.Frames) > 0 {
+		if m.CurrentFrame == len(m.Frames)-1 {
+			m.Plays++
+			if m.finished() {
+				m.Paused = true
+				if m.Inline {
+					return m, m.quit()
+				}
+				return m, nil
+			}
+		}
 		m.CurrentFrame = (m.CurrentFrame + 1) % len(m.Frames)
+		m.refreshViewport()
+		m.refreshFilmstrip()
 		return m, m.nextFrame()
 	}
 	return m, nil
@@ -317,41 +706,108 @@ func (m *model) handleProcessingComplete() (tea.Model, tea.Cmd) {
 	m.Ready = true
 	m.Loading = false
 	m.CurrentFrame = 0
+	m.Plays = 0
+
+	// The first viewport render starts centred, later ones keep the pan
+	if m.ViewportFrame == "" {
+		m.centerViewport()
+	} else {
+		m.clampPan()
+		m.refreshViewport()
+	}
+	m.refreshFilmstrip()
+	m.clampCursor()
+
+	// A file picked during the render replaces the one just rendered, and
+	// is rendered for any view change made meanwhile
+	stale := m.applyPendingView()
+	if m.fileQueued {
+		m.fileQueued = false
+		if m.queuedFile != m.PlaylistIndex {
+			return m, m.showFile(m.queuedFile)
+		}
+	}
+
+	// The view changed during the render, so render once more
+	if stale {
+		return m, m.reprocess()
+	}
+
+	preload := m.preloadNeighbours()
 	if !m.Paused {
-		return m, m.nextFrame()
+		return m, tea.Batch(m.nextFrame(), preload)
 	}
-	return m, nil
+	return m, preload
 }
 
 func (m *model) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	oldWidth, oldHeight := m.Width, m.Height
 	m.Width, m.Height = msg.Width, msg.Height
+	if m.Inline {
+		m.Height = min(m.Height, m.InlineHeight)
+	}
+
+	// Grid tiles keep their size, so only the layout changes
+	var gridCmd tea.Cmd
+	if m.ShowGrid {
+		m.scrollGrid()
+		gridCmd = m.loadVisibleTiles()
+	}
+	if m.Browser != nil {
+		gridCmd = tea.Batch(gridCmd, m.resizeBrowser())
+	}
 
 	// Ignore initial size (handled by Init) or if already processing
 	if oldWidth == 0 || oldHeight == 0 {
-		return m, nil
+		return m, gridCmd
 	}
 
 	// Ignore if size didn't actually change
 	if oldWidth == m.Width && oldHeight == m.Height {
-		return m, nil
+		return m, gridCmd
 	}
 
-	// Ignore if we're currently loading - just update dimensions
-	// The resize will be handled after current processing completes
+	// While loading only record the new size; frames are rendered for it
+	// after the current render completes
 	if m.Loading {
-		return m, nil
+		m.reprocessPending = true
+		return m, gridCmd
 	}
+	if m.pendingSource != "" || m.GIF == nil {
+		return m, gridCmd
+	}
+
+	return m, tea.Batch(gridCmd, m.reprocess())
+}
 
-	// Process the resize
+// applyPendingView applies the view changes reported while frames were
+// rendering, reporting whether the frames need rendering again
+func (m *model) applyPendingView() bool {
+	if m.pendingCellAspect > 0 {
+		m.CellAspect = m.pendingCellAspect
+		m.pendingCellAspect = 0
+	}
+	if m.pendingBackground != nil {
+		m.TerminalBackground = m.pendingBackground
+		m.pendingBackground = nil
+	}
+
+	pending := m.reprocessPending
+	m.reprocessPending = false
+	return pending
+}
+
+// reprocess discards the rendered frames and renders them again
+func (m *model) reprocess() tea.Cmd {
 	m.Ready = false
 	m.Loading = true
 	m.LoadingFrame = ""
 	m.LoadingRows = 0
 	m.TotalRows = 0
 	m.Frames = []string{}
+	m.Thumbnails = nil
 
-	return m, m.ProcessGIF(m.program)
+	return m.ProcessGIF(m.program)
 }
 
 // nextFrame schedules the next frame based on GIF delay
@@ -360,12 +816,7 @@ func (m *model) nextFrame() tea.Cmd {
 		return nil
 	}
 
-	delay := m.GIF.Delay[m.CurrentFrame]
-	if delay == 0 {
-		delay = 10 // Default to 100ms if no delay specified
-	}
-
-	return tea.Tick(time.Duration(delay)*10*time.Millisecond, func(t time.Time) tea.Msg {
+	return tea.Tick(playbackDelay(m.GIF, m.CurrentFrame, m.Speed), func(t time.Time) tea.Msg {
 		return frameMsg(0)
 	})
 }
@@ -376,7 +827,26 @@ func (m *model) nextFrame() tea.Cmd {
 
 func (m model) View() tea.View {
 	var v tea.View
-	v.AltScreen = true
+	if m.Inline {
+		if m.quitting {
+			v.Content = m.renderExitFrame()
+			return v
+		}
+	} else {
+		v.AltScreen = true
+		v.MouseMode = tea.MouseModeCellMotion
+	}
+
+	if m.ShowBrowser {
+		v.Content = m.renderBrowser()
+		return v
+	}
+
+	// Grid gallery plays independently of the main view
+	if m.ShowGrid {
+		v.Content = m.renderGrid()
+		return v
+	}
 
 	// Progressive loading view
 	if m.Loading && m.LoadingFrame != "" {
@@ -405,7 +875,7 @@ func (m model) renderLoadingView() *lipgloss.Layer {
 
 	status := fmt.Sprintf(" Loading... %d/%d rows ", m.LoadingRows, m.TotalRows)
 	statusText := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("86")).
+		Foreground(m.colors().Loading).
 		Render(status)
 
 	layers := []*lipgloss.Layer{
@@ -417,13 +887,25 @@ func (m model) renderLoadingView() *lipgloss.Layer {
 }
 
 func (m model) renderInitialLoading() *lipgloss.Layer {
+	message := "Loading GIF..."
+	if m.LoadError != "" {
+		keys := m.keyMap()
+		message = m.LoadError + "\n\n" + keyHints(
+			keyHint(keys.PrevFile, "previous file"),
+			keyHint(keys.NextFile, "next file"),
+			keyHint(keys.Quit, "quit"),
+		)
+	} else if label := m.playlistLabel(); label != "" {
+		message = "Loading " + label + "..."
+	}
+
 	content := lipgloss.NewStyle().
 		Width(m.Width).
 		Height(m.Height).
 		AlignHorizontal(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Foreground(lipgloss.Color("86")).
-		Render("Loading GIF...")
+		Foreground(m.colors().Loading).
+		Render(message)
 
 	return lipgloss.NewLayer(content)
 }
@@ -431,16 +913,47 @@ func (m model) renderInitialLoading() *lipgloss.Layer {
 func (m model) renderPlaybackView() *lipgloss.Layer {
 	frame := lipgloss.NewStyle().
 		Width(m.Width).
-		Height(m.Height).
+		Height(m.imageHeight()).
 		AlignHorizontal(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
-		Render(m.Frames[m.CurrentFrame])
+		Render(m.currentFrameView())
 
 	layers := []*lipgloss.Layer{
 		lipgloss.NewLayer(frame).Z(0),
 		lipgloss.NewLayer(m.renderStatus()).X(1).Y(0).Z(5),
 	}
 
+	if m.showsFilmstrip() {
+		left, top := m.filmstripBounds()
+		layers = append(layers, lipgloss.NewLayer(m.renderFilmstrip()).X(left).Y(top).Z(1))
+	}
+
+	layers = append(layers, m.renderRectOutline()...)
+
+	if m.Inspecting {
+		if cursor, cell, ok := m.renderCursor(); ok {
+			layers = append(layers, lipgloss.NewLayer(cursor).X(cell.X).Y(cell.Y).Z(2))
+		}
+		if inspector := m.renderInspector(); inspector != "" {
+			layers = append(layers, lipgloss.NewLayer(inspector).X(1).Y(1).Z(7))
+		}
+	}
+
+	if m.ShowPalette {
+		if palette := m.renderPalette(); palette != "" {
+			layers = append(layers, lipgloss.NewLayer(palette).X(1).Y(1).Z(9))
+		}
+	}
+
+	if m.ShowInfo {
+		info := m.renderInfo()
+		infoLayer := lipgloss.NewLayer(info).
+			X(max(0, m.Width-lipgloss.Width(info)-1)).
+			Y(1).
+			Z(8)
+		layers = append(layers, infoLayer)
+	}
+
 	if m.ShowHelp {
 		help := m.renderHelp()
 		helpWidth := lipgloss.Width(help)
@@ -456,34 +969,79 @@ func (m model) renderPlaybackView() *lipgloss.Layer {
 }
 
 func (m model) renderStatus() string {
+	if m.Saving {
+		return m.renderSavePrompt()
+	}
+
 	icon := "▶"
 	if m.Paused {
 		icon = "⏸"
 	}
 
 	status := fmt.Sprintf(" %s %d/%d ", icon, m.CurrentFrame+1, len(m.Frames))
-	return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(status)
+	if speed := m.speedLabel(); speed != "" {
+		status += speed + " "
+	}
+	if zoom := m.zoomLabel(); zoom != "" {
+		status += zoom + " "
+	}
+	if file := m.playlistLabel(); file != "" {
+		status += file + " "
+	}
+	if overlay := m.overlayLabel(); overlay != "" {
+		status += overlay + " "
+	}
+	if bg := m.Background.label(); bg != "" {
+		status += bg + " "
+	}
+	if m.PixelInfo != "" {
+		status += m.PixelInfo + " "
+	}
+	if m.SaveStatus != "" {
+		status += m.SaveStatus + " "
+	}
+	return m.colors().label().Render(status)
+}
+
+// mouseHelp lists the fixed mouse actions shown below the key bindings
+var mouseHelp = [][2]string{
+	{"Click", "Pause/Resume or move cursor"},
+	{"Wheel", "Previous/Next frame"},
+	{"Ctrl+Wheel", "Zoom in/out"},
+	{"Drag", "Pan when zoomed"},
+	{"Thumbnail", "Jump to frame"},
+	{"R-click", "Inspect pixel"},
 }
 
 func (m model) renderHelp() string {
-	title := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("213")).
-		Bold(true).
-		Render("  Keybindings")
+	t := m.colors()
+	title := t.title().Render("Keybindings")
+
+	// Sections are split over two columns to fit shorter terminals
+	sections := m.keyMap().sections()
+	half := (len(sections) + 1) / 2
+
+	var left, right []string
+	for i, section := range sections {
+		rendered := t.renderHelpSection(section.Title, section.helpRows())
+		if i < half {
+			left = append(left, rendered)
+		} else {
+			right = append(right, rendered)
+		}
+	}
+	right = append(right, t.renderHelpSection("Mouse", mouseHelp))
 
-	helpText := `
-  Space      Pause/Resume
-  n / →      Next frame
-  p / ←      Previous frame
-  ?          Toggle help
-  q / Ctrl+C Quit
-`
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		strings.Join(left, "\n\n"),
+		"    ",
+		strings.Join(right, "\n\n"))
 
-	content := lipgloss.JoinVertical(lipgloss.Left, title, helpText)
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", columns)
 
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("213")).
+		BorderForeground(t.Accent).
 		Padding(1, 2).
 		Render(content)
 }
@@ -497,54 +1055,234 @@ func isURL(s string) bool {
 }
 
 func loadGIF(source string) (*gif.GIF, error) {
+	g, _, err := loadGIFWithStats(source)
+	return g, err
+}
+
+// loadGIFWithStats loads a GIF and records its size and decode time
+func loadGIFWithStats(source string) (*gif.GIF, loadStats, error) {
+	var stats loadStats
 	var reader io.ReadCloser
 	var err error
 
 	if isURL(source) {
-		fmt.Printf("Downloading GIF from %s...\n", source)
 		resp, err := http.Get(source)
 		if err != nil {
-			return nil, fmt.Errorf("failed to download: %w", err)
+			return nil, stats, fmt.Errorf("failed to download: %w", err)
 		}
 		if resp.StatusCode != http.StatusOK {
 			_ = resp.Body.Close()
-			return nil, fmt.Errorf("HTTP error: %s", resp.Status)
+			return nil, stats, fmt.Errorf("HTTP error: %s", resp.Status)
 		}
 		reader = resp.Body
 	} else {
 		file, err := os.Open(source)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open file: %w", err)
+			return nil, stats, fmt.Errorf("failed to open file: %w", err)
 		}
 		reader = file
 	}
 	defer reader.Close()
 
-	gifImage, err := gif.DecodeAll(reader)
+	counter := &countingReader{r: reader}
+	start := time.Now()
+	gifImage, err := gif.DecodeAll(counter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode GIF: %w", err)
+		return nil, stats, fmt.Errorf("failed to decode GIF: %w", err)
 	}
+	stats.DecodeTime = time.Since(start)
+	stats.FileSize = counter.n
 
-	return gifImage, nil
+	return gifImage, stats, nil
 }
 
 // ============================================================================
 // Main
 // ============================================================================
 
-// Run starts the JIF GIF viewer with the given source (file path or URL)
-func Run(source string) error {
-	gifImage, err := loadGIF(source)
+// Options configures the viewer
+type Options struct {
+	// Fit is how the image is mapped to the terminal: contain, cover,
+	// stretch or original. Empty means contain.
+	Fit string
+
+	// CellAspect is the terminal cell height/width ratio. Zero detects it
+	// from the terminal, falling back to 2.
+	CellAspect float64
+
+	// Grid starts in the grid gallery when several files are given
+	Grid bool
+
+	// Speed multiplies the playback rate. Zero means normal speed.
+	Speed float64
+
+	// Loop is forever, gif to honour the file's loop count, or a number of
+	// plays. Empty means forever.
+	Loop string
+
+	// Color forces a colour mode: truecolor, 256 or 16. Empty or auto
+	// detects it from the terminal.
+	Color string
+
+	// Keymap is the keybinding preset: default, vim or media. Empty means
+	// default.
+	Keymap string
+
+	// Keys rebinds actions by name on top of the preset; an empty list
+	// disables the action
+	Keys map[string][]string
+
+	// Inline plays in the normal scrollback instead of the alternate screen,
+	// Height rows tall, and leaves a frame printed on exit: ExitFrame
+	// (1-based), or the one on screen when it is 0. Zero Height means 20.
+	// The jif command plays inline with Loop set to LoopFinite unless
+	// --loop is given, so that it ends on its own.
+	Inline    bool
+	Height    int
+	ExitFrame int
+
+	// Width, Frame and Time are used by Cat: the output width in columns
+	// (0 uses the terminal width), and the 1-based frame or the playback time
+	// to print. Both zero prints the first frame.
+	Width int
+	Frame int
+	Time  time.Duration
+
+	// Background is what transparent pixels are drawn against: terminal,
+	// checkerboard or a #rrggbb colour. Empty means terminal.
+	Background string
+
+	// Theme names the UI colours: auto, dark, light, high-contrast or
+	// high-contrast-light. Empty means auto.
+	Theme string
+
+	// ThemeColors overrides the theme's accent, muted or loading colour
+	ThemeColors map[string]string
+}
+
+// browseDir returns the directory to open in the file browser, if args ask
+// for one: no arguments browse the working directory
+func browseDir(args []string, opts Options) (string, bool) {
+	if len(args) == 0 {
+		return ".", true
+	}
+	if len(args) != 1 || opts.Grid {
+		return "", false
+	}
+	info, err := os.Stat(args[0])
+	return args[0], err == nil && info.IsDir()
+}
+
+// Run starts the JIF GIF viewer with the given sources (file paths, globs,
+// directories or URLs); a single directory or no sources opens the file
+// browser
+func Run(args []string, opts Options) error {
+	fit, err := parseFitMode(opts.Fit)
+	if err != nil {
+		return err
+	}
+
+	cellAspect, err := parseCellAspect(opts.CellAspect)
+	if err != nil {
+		return err
+	}
+
+	speed, err := parseSpeed(opts.Speed)
+	if err != nil {
+		return err
+	}
+
+	loop, err := parseLoop(opts.Loop)
+	if err != nil {
+		return err
+	}
+
+	profile, forceProfile, err := parseColorMode(opts.Color)
+	if err != nil {
+		return err
+	}
+
+	keys, err := loadKeyMap(opts.Keymap, opts.Keys)
 	if err != nil {
-		return fmt.Errorf("loading GIF: %w", err)
+		return err
+	}
+
+	theme, err := loadTheme(opts.Theme, opts.ThemeColors)
+	if err != nil {
+		return err
+	}
+
+	bg, err := parseBackground(opts.Background)
+	if err != nil {
+		return err
+	}
+
+	if err := parseInline(opts); err != nil {
+		return err
+	}
+	inlineHeight := opts.Height
+	if inlineHeight == 0 {
+		inlineHeight = defaultInlineHeight
 	}
 
 	m := model{
-		GIF:    gifImage,
-		Paused: false,
+		Paused:          false,
+		Fit:             fit,
+		CellAspect:      cellAspect,
+		cellAspectFixed: cellAspect > 0,
+		ShowGrid:        opts.Grid,
+		Speed:           speed,
+		Loop:            loop,
+		Background:      bg,
+		Inline:          opts.Inline,
+		InlineHeight:    inlineHeight,
+		ExitFrame:       opts.ExitFrame,
+		keys:            keys,
+		theme:           theme,
+		autoTheme:       opts.Theme == "" || strings.EqualFold(opts.Theme, themeAuto),
+		themeColors:     opts.ThemeColors,
+	}
+
+	if dir, ok := browseDir(args, opts); ok {
+		if opts.Inline {
+			return fmt.Errorf("--inline needs a GIF to play, not a directory")
+		}
+
+		// Files are loaded when picked in the browser
+		if m.Browser, err = newBrowser(dir); err != nil {
+			return err
+		}
+		m.ShowBrowser = true
+	} else {
+		sources, err := expandSources(args)
+		if err != nil {
+			return err
+		}
+
+		source := sources[0]
+		if isURL(source) {
+			fmt.Printf("Downloading GIF from %s...\n", source)
+		}
+
+		gifImage, stats, err := loadGIFWithStats(source)
+		if err != nil {
+			return fmt.Errorf("loading GIF: %w", err)
+		}
+
+		m.GIF = gifImage
+		m.Stats = stats
+		m.Playlist = sources
+	}
+	if !m.cellAspectFixed {
+		m.CellAspect = queryCellAspect()
+	}
+
+	var programOpts []tea.ProgramOption
+	if forceProfile {
+		programOpts = append(programOpts, tea.WithColorProfile(profile))
 	}
 
-	p := tea.NewProgram(&m)
+	p := tea.NewProgram(&m, programOpts...)
 	m.program = p
 
 	if _, err := p.Run(); err != nil {
@@ -50,7 +50,7 @@ func TestRenderHalfBlockChar(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := renderHalfBlockChar(tt.topColor, tt.bottomColor)
+			result := renderHalfBlockChar(tt.topColor, tt.bottomColor, 2)
 
 			// Check that result contains expected characters
 			foundMatch := false
@@ -111,8 +111,9 @@ func TestCalculateImageSize(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &model{
-				Width:  tt.termWidth,
-				Height: tt.termHeight,
+				Width:      tt.termWidth,
+				Height:     tt.termHeight,
+				PixelChars: 2,
 			}
 
 			img := image.NewRGBA(image.Rect(0, 0, tt.imgWidth, tt.imgHeight))
@@ -377,10 +378,9 @@ func TestHandleKeyPress(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := &model{
+				player: player{Frames: []string{"frame1", "frame2", "frame3"}, GIF: &gif.GIF{Delay: []int{10, 10, 10}}},
 				Paused: tt.initialPause,
 				Ready:  tt.initialReady,
-				Frames: []string{"frame1", "frame2", "frame3"},
-				GIF:    &gif.GIF{Delay: []int{10, 10, 10}},
 			}
 
 			// We can't directly test handleKeyPress with tea.KeyMsg easily
@@ -402,11 +402,13 @@ func TestHandleKeyPress(t *testing.T) {
 
 func TestHandleFrameAdvance(t *testing.T) {
 	m := &model{
-		Paused:       false,
-		Ready:        true,
-		CurrentFrame: 0,
-		Frames:       []string{"frame1", "frame2", "frame3"},
-		GIF:          &gif.GIF{Delay: []int{10, 10, 10}},
+		player: player{
+			CurrentFrame: 0,
+			Frames:       []string{"frame1", "frame2", "frame3"},
+			GIF:          &gif.GIF{Delay: []int{10, 10, 10}},
+		},
+		Paused: false,
+		Ready:  true,
 	}
 
 	// Simulate frame advance
@@ -436,8 +438,8 @@ func TestHandleFrameAdvance(t *testing.T) {
 
 func TestHandleProgress(t *testing.T) {
 	m := &model{
-		Loading: true,
-		Ready:   false,
+		player: player{Loading: true},
+		Ready:  false,
 	}
 
 	msg := progressMsg{
@@ -476,11 +478,13 @@ func TestHandleProgress(t *testing.T) {
 
 func TestHandleProcessingComplete(t *testing.T) {
 	m := &model{
-		Loading: true,
-		Ready:   false,
-		Paused:  false,
-		GIF:     &gif.GIF{Delay: []int{10, 10}},
-		Frames:  []string{"frame1", "frame2"},
+		player: player{
+			Loading: true,
+			GIF:     &gif.GIF{Delay: []int{10, 10}},
+			Frames:  []string{"frame1", "frame2"},
+		},
+		Ready:  false,
+		Paused: false,
 	}
 
 	_, _ = m.handleProcessingComplete()
@@ -499,12 +503,14 @@ func TestHandleProcessingComplete(t *testing.T) {
 func TestResizeHandling(t *testing.T) {
 	t.Run("ignores resize while loading", func(t *testing.T) {
 		m := &model{
-			Width:   80,
-			Height:  40,
-			Ready:   false,
-			Loading: true, // Currently loading
-			GIF:     &gif.GIF{Delay: []int{10}},
-			Frames:  []string{"frame1"},
+			player: player{
+				Loading: true, // Currently loading
+				GIF:     &gif.GIF{Delay: []int{10}},
+				Frames:  []string{"frame1"},
+			},
+			Width:  80,
+			Height: 40,
+			Ready:  false,
 		}
 
 		msg := tea.WindowSizeMsg{Width: 100, Height: 50}
@@ -517,16 +523,24 @@ func TestResizeHandling(t *testing.T) {
 		if cmd != nil {
 			t.Error("Should not trigger processing while already loading")
 		}
+
+		// The frames are rendered for the new size once loading completes
+		_, cmd = m.Update(processingCompleteMsg{})
+		if cmd == nil || !m.Loading {
+			t.Error("Should re-render after the current processing completes")
+		}
 	})
 
 	t.Run("processes resize when ready", func(t *testing.T) {
 		m := &model{
-			Width:   80,
-			Height:  40,
-			Ready:   true,
-			Loading: false,
-			GIF:     &gif.GIF{Delay: []int{10}},
-			Frames:  []string{"frame1"},
+			player: player{
+				Loading: false,
+				GIF:     &gif.GIF{Delay: []int{10}},
+				Frames:  []string{"frame1"},
+			},
+			Width:  80,
+			Height: 40,
+			Ready:  true,
 		}
 
 		msg := tea.WindowSizeMsg{Width: 100, Height: 50}
@@ -565,12 +579,14 @@ func TestHandleWindowResize(t *testing.T) {
 
 	t.Run("handles actual resize", func(t *testing.T) {
 		m := &model{
-			Width:   80,
-			Height:  40,
-			Ready:   true,
-			Loading: false,
-			GIF:     &gif.GIF{Delay: []int{10}},
-			Frames:  []string{"frame1"},
+			player: player{
+				Loading: false,
+				GIF:     &gif.GIF{Delay: []int{10}},
+				Frames:  []string{"frame1"},
+			},
+			Width:  80,
+			Height: 40,
+			Ready:  true,
 		}
 
 		msg := tea.WindowSizeMsg{Width: 100, Height: 50}
@@ -600,10 +616,147 @@ func TestHandleWindowResize(t *testing.T) {
 	})
 }
 
+func TestHandleMouse(t *testing.T) {
+	newModel := func() *model {
+		img := image.NewRGBA(image.Rect(0, 0, 30, 20))
+		img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+		return &model{
+			player: player{
+				Frames:     []string{"frame1", "frame2", "frame3"},
+				Composited: []*image.RGBA{img, img, img},
+				GIF:        &gif.GIF{Delay: []int{10, 10, 10}},
+			},
+			Width:  100,
+			Height: 20,
+			Ready:  true,
+		}
+	}
+
+	t.Run("left click toggles pause", func(t *testing.T) {
+		m := newModel()
+
+		_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseLeft})
+		_, _ = m.Update(tea.MouseReleaseMsg{Button: tea.MouseLeft})
+		if !m.Paused {
+			t.Error("left click should pause playback")
+		}
+
+		_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseLeft})
+		_, cmd := m.Update(tea.MouseReleaseMsg{Button: tea.MouseLeft})
+		if m.Paused {
+			t.Error("second left click should resume playback")
+		}
+		if cmd == nil {
+			t.Error("resuming should schedule the next frame")
+		}
+	})
+
+	t.Run("wheel steps frames", func(t *testing.T) {
+		m := newModel()
+
+		_, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+		if m.CurrentFrame != 1 || !m.Paused {
+			t.Errorf("wheel down: CurrentFrame = %d, Paused = %v", m.CurrentFrame, m.Paused)
+		}
+
+		_, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp})
+		_, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp})
+		if m.CurrentFrame != 2 {
+			t.Errorf("wheel up should wrap backwards, got frame %d", m.CurrentFrame)
+		}
+	})
+
+	t.Run("right click inspects pixel", func(t *testing.T) {
+		m := newModel()
+
+		// 30x20 image in 100x20 terminal renders 30 pixels wide at x=20
+		_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseRight, X: 20, Y: 0})
+		if m.PixelInfo != "(0,0) #ff0000" {
+			t.Errorf("PixelInfo = %q, want %q", m.PixelInfo, "(0,0) #ff0000")
+		}
+
+		_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseRight, X: 99, Y: 0})
+		if m.PixelInfo != "" {
+			t.Errorf("click outside image should clear PixelInfo, got %q", m.PixelInfo)
+		}
+	})
+}
+
+func TestCellToSource(t *testing.T) {
+	m := &model{
+		player: player{Composited: []*image.RGBA{image.NewRGBA(image.Rect(0, 0, 30, 20))}},
+		Width:  100,
+		Height: 20,
+	}
+
+	tests := []struct {
+		x, y   int
+		want   image.Point
+		wantOK bool
+	}{
+		{20, 0, image.Pt(0, 0), true},
+		{79, 19, image.Pt(29, 19), true},
+		{50, 10, image.Pt(15, 10), true},
+		{19, 0, image.Point{}, false},
+		{80, 0, image.Point{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := m.cellToSource(tt.x, tt.y)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("cellToSource(%d, %d) = %v, %v, want %v, %v", tt.x, tt.y, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
 // ============================================================================
 // Integration Tests with Real GIF Files
 // ============================================================================
 
+func TestCompositeFramesDisposal(t *testing.T) {
+	// disposal.gif draws a red canvas, a green circle and a blue square
+	// marked to restore the previous canvas. Keeping the circle on screen
+	// and adding a final empty frame shows what the square is undone to.
+	g, err := loadGIF("../testdata/disposal.gif")
+	if err != nil {
+		t.Fatalf("loadGIF() error = %v", err)
+	}
+	g.Disposal[1] = gif.DisposalNone
+	g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Transparent}))
+	g.Delay = append(g.Delay, 30)
+	g.Disposal = append(g.Disposal, gif.DisposalNone)
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	green := color.RGBA{G: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+	tests := []struct {
+		frame  int
+		center color.RGBA // inside the circle and the square
+		corner color.RGBA // inside the square only
+	}{
+		{frame: 0, center: red, corner: red},
+		{frame: 1, center: green, corner: red},
+		{frame: 2, center: blue, corner: blue},
+		{frame: 3, center: green, corner: red},
+	}
+
+	var frames []*image.RGBA
+	compositeFrames(g, func(i int, img *image.RGBA) {
+		frames = append(frames, img)
+	})
+	if len(frames) != len(tests) {
+		t.Fatalf("compositeFrames() gave %d frames, want %d", len(frames), len(tests))
+	}
+	for _, tt := range tests {
+		if got := frames[tt.frame].RGBAAt(24, 24); got != tt.center {
+			t.Errorf("frame %d center = %v, want %v", tt.frame, got, tt.center)
+		}
+		if got := frames[tt.frame].RGBAAt(13, 13); got != tt.corner {
+			t.Errorf("frame %d corner = %v, want %v", tt.frame, got, tt.corner)
+		}
+	}
+}
+
 func TestProcessGIFWithTestFiles(t *testing.T) {
 	testFiles := []struct {
 		name     string
@@ -624,7 +777,7 @@ func TestProcessGIFWithTestFiles(t *testing.T) {
 			}
 
 			m := &model{
-				GIF:    g,
+				player: player{GIF: g},
 				Width:  80,
 				Height: 40,
 			}
@@ -662,7 +815,7 @@ func BenchmarkRenderHalfBlockChar(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = renderHalfBlockChar(top, bottom)
+		_ = renderHalfBlockChar(top, bottom, 2)
 	}
 }
 
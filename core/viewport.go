@@ -0,0 +1,286 @@
+package jif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/nfnt/resize"
+)
+
+// zoomLevels lists the supported pixel-per-cell zoom factors; zoom 0 fits the
+// whole canvas to the terminal
+var zoomLevels = []int{0, 1, 2, 4, 8}
+
+// dragState tracks a left-button drag used for panning
+type dragState struct {
+	active bool
+	moved  bool
+	origin image.Point
+	pan    image.Point
+}
+
+// ============================================================================
+// Zoom & Pan
+// ============================================================================
+
+// zoomIn moves to the next zoom level, keeping the viewport centre in place
+func (m *model) zoomIn() {
+	for _, z := range zoomLevels {
+		if z > m.scale() {
+			m.setZoom(z)
+			return
+		}
+	}
+}
+
+// zoomOut moves to the previous zoom level, keeping the viewport centre in place
+func (m *model) zoomOut() {
+	for i := len(zoomLevels) - 1; i >= 0; i-- {
+		if zoomLevels[i] < m.Zoom {
+			m.setZoom(zoomLevels[i])
+			return
+		}
+	}
+}
+
+// setZoom changes the zoom level and re-centres the viewport
+func (m *model) setZoom(zoom int) {
+	if len(m.Composited) == 0 {
+		m.Zoom = zoom
+		return
+	}
+
+	src, _, _ := m.viewport()
+	m.Zoom = zoom
+	m.centerOn(image.Pt((src.Min.X+src.Max.X)/2, (src.Min.Y+src.Max.Y)/2))
+}
+
+// centerViewport centres the viewport on the canvas
+func (m *model) centerViewport() {
+	if len(m.Composited) == 0 {
+		return
+	}
+
+	b := m.Composited[0].Bounds()
+	m.centerOn(image.Pt((b.Min.X+b.Max.X)/2, (b.Min.Y+b.Max.Y)/2))
+}
+
+// centerOn pans so that the source pixel pt is in the middle of the viewport
+func (m *model) centerOn(pt image.Point) {
+	visW, visH := m.visibleSourceSize()
+	m.PanX = pt.X - visW/2
+	m.PanY = pt.Y - visH/2
+	m.clampPan()
+	m.refreshViewport()
+}
+
+// pan moves the viewport by dx, dy source pixels
+func (m *model) pan(dx, dy int) {
+	m.PanX += dx
+	m.PanY += dy
+	m.clampPan()
+	m.refreshViewport()
+}
+
+// handleDirection pans the viewport by an eighth of the screen in direction
+// dx, dy when zoomed, otherwise left and right step frames
+func (m *model) handleDirection(dx, dy int) {
+	if m.scale() == 0 {
+		if dx != 0 {
+			m.stepFrame(dx)
+		}
+		return
+	}
+
+	visW, visH := m.visibleSourceSize()
+	m.pan(dx*max(1, visW/8), dy*max(1, visH/8))
+}
+
+// scale returns the pixel-per-cell factor in use, or 0 when the canvas is
+// fitted to the terminal; original size behaves like 1:1 zoom
+func (m model) scale() int {
+	if m.Zoom == 0 && m.Fit == fitOriginal {
+		return 1
+	}
+	return m.Zoom
+}
+
+// visibleSourceSize returns how many source pixels fit on screen at the
+// current zoom level
+func (m model) visibleSourceSize() (width, height int) {
+	scale := m.scale()
+	if scale == 0 {
+		if len(m.Composited) == 0 {
+			return 0, 0
+		}
+		b := m.fitSource(m.Composited[0].Bounds())
+		return b.Dx(), b.Dy()
+	}
+
+	// At 1:1 each pixel spans one pixel column and enough halfblock rows to
+	// stay square
+	rows := float64(m.imageHeight()*2) / (float64(scale) * m.pixelStretch())
+	return max(1, m.Width/m.charsPerPixel()/scale), max(1, int(rows))
+}
+
+// clampPan keeps the viewport inside the canvas
+func (m *model) clampPan() {
+	if len(m.Composited) == 0 {
+		return
+	}
+
+	canvas := m.Composited[0].Bounds()
+	visW, visH := m.visibleSourceSize()
+	m.PanX = max(0, min(m.PanX, canvas.Dx()-visW))
+	m.PanY = max(0, min(m.PanY, canvas.Dy()-visH))
+}
+
+// viewport returns the source rectangle shown on screen and the pixel size it
+// is scaled to before halfblock rendering
+func (m model) viewport() (src image.Rectangle, width, height int) {
+	canvas := m.Composited[0].Bounds()
+	scale := m.scale()
+	if scale == 0 {
+		width, height = m.calculateImageSize(m.Composited[0])
+		return m.fitSource(canvas), width, height
+	}
+
+	visW, visH := m.visibleSourceSize()
+	src = image.Rect(m.PanX, m.PanY, m.PanX+visW, m.PanY+visH).Add(canvas.Min).Intersect(canvas)
+	height = int(float64(src.Dy()*scale) * m.pixelStretch())
+	return src, src.Dx() * scale, min(height, m.imageHeight()*2)
+}
+
+// refreshViewport re-renders the visible part of the current frame when
+// zoomed or when an overlay changes the image
+func (m *model) refreshViewport() {
+	m.OverlayInfo = ""
+	if m.CurrentFrame >= len(m.Composited) || !m.rendersViewport() {
+		m.ViewportFrame = ""
+		return
+	}
+
+	img, info := m.frameImage(m.CurrentFrame)
+	m.OverlayInfo = info
+
+	// Fitted frames are pre-rendered, so only overlaid ones are drawn here
+	if m.scale() == 0 {
+		m.ViewportFrame = m.fittedDiff(m.CurrentFrame)
+		return
+	}
+
+	src, width, height := m.viewport()
+	if src.Empty() {
+		m.ViewportFrame = ""
+		return
+	}
+
+	frame := subImage(img, src)
+	resized := resize.Resize(uint(width), uint(height), frame, resize.NearestNeighbor)
+	flat := m.Background.flatten(resized, m.TerminalBackground)
+	m.ViewportFrame = renderHalfBlocks(flat, m.charsPerPixel(), nil)
+}
+
+// rendersViewport reports whether the current frame is drawn on demand rather
+// than taken from the pre-rendered frames
+func (m model) rendersViewport() bool {
+	return m.scale() > 0 || m.Overlay == overlayDiff
+}
+
+// currentFrameView returns the rendered content for the current frame
+func (m model) currentFrameView() string {
+	if m.rendersViewport() && m.ViewportFrame != "" {
+		return m.ViewportFrame
+	}
+	return m.Frames[m.CurrentFrame]
+}
+
+// zoomLabel describes the zoom level and non-default fit mode for the status bar
+func (m model) zoomLabel() string {
+	if m.Zoom > 0 {
+		return fmt.Sprintf("%dx", m.Zoom)
+	}
+	if m.Fit != fitContain {
+		return m.Fit.String()
+	}
+	return ""
+}
+
+// ============================================================================
+// Pixel Mapping
+// ============================================================================
+
+// imageBounds returns the cells occupied by the rendered frame, mirroring the
+// centring done in renderPlaybackView
+func (m model) imageBounds() image.Rectangle {
+	if len(m.Composited) == 0 {
+		return image.Rectangle{}
+	}
+
+	_, width, height := m.viewport()
+	cols := width * m.charsPerPixel()
+	rows := (height + 1) / 2
+
+	// Rendered frames end with a newline, which lipgloss counts as a line
+	left := max(0, (m.Width-cols)/2)
+	top := max(0, (m.imageHeight()-(rows+1))/2)
+
+	return image.Rect(left, top, left+cols, top+rows)
+}
+
+// cellToSource maps a terminal cell to the pixel of the composited frame
+// drawn in its top half
+func (m model) cellToSource(x, y int) (image.Point, bool) {
+	bounds := m.imageBounds()
+	if !image.Pt(x, y).In(bounds) {
+		return image.Point{}, false
+	}
+
+	src, width, height := m.viewport()
+	if width == 0 || height == 0 {
+		return image.Point{}, false
+	}
+
+	px := (x - bounds.Min.X) / m.charsPerPixel()
+	py := (y - bounds.Min.Y) * 2
+
+	return image.Pt(
+		src.Min.X+px*src.Dx()/width,
+		src.Min.Y+py*src.Dy()/height,
+	), true
+}
+
+// describePixel reports the source coordinates and colour under a terminal cell
+func (m model) describePixel(x, y int) string {
+	pt, ok := m.cellToSource(x, y)
+	if !ok || m.CurrentFrame >= len(m.Composited) {
+		return ""
+	}
+
+	c := color.NRGBAModel.Convert(m.Composited[m.CurrentFrame].At(pt.X, pt.Y)).(color.NRGBA)
+	info := fmt.Sprintf("(%d,%d) #%02x%02x%02x", pt.X, pt.Y, c.R, c.G, c.B)
+	if c.A != 0xff {
+		info += fmt.Sprintf(" α%d", c.A)
+	}
+	return info
+}
+
+// sourceToCell maps a pixel of the composited frame to the terminal cell that
+// draws it, reporting whether it falls in the bottom half of that cell
+func (m model) sourceToCell(pt image.Point) (cell image.Point, bottom bool, ok bool) {
+	if len(m.Composited) == 0 {
+		return image.Point{}, false, false
+	}
+
+	src, width, height := m.viewport()
+	if !pt.In(src) || src.Dx() == 0 || src.Dy() == 0 {
+		return image.Point{}, false, false
+	}
+
+	px := (pt.X - src.Min.X) * width / src.Dx()
+	py := (pt.Y - src.Min.Y) * height / src.Dy()
+
+	bounds := m.imageBounds()
+	return image.Pt(bounds.Min.X+px*m.charsPerPixel(), bounds.Min.Y+py/2), py%2 == 1, true
+}
@@ -0,0 +1,168 @@
+package jif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func newZoomModel() *model {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	return &model{
+		player: player{
+			Frames:     []string{"frame1", "frame2"},
+			Composited: []*image.RGBA{img, img},
+			GIF:        &gif.GIF{Delay: []int{10, 10}},
+		},
+		Width:      40,
+		Height:     10,
+		PixelChars: 2,
+		CellAspect: 4,
+		Ready:      true,
+		Paused:     true,
+	}
+}
+
+func TestZoomLevels(t *testing.T) {
+	m := newZoomModel()
+
+	m.zoomIn()
+	if m.Zoom != 1 {
+		t.Fatalf("zoomIn() from fit: Zoom = %d, want 1", m.Zoom)
+	}
+	if m.ViewportFrame == "" {
+		t.Error("zooming should render the viewport")
+	}
+
+	m.zoomIn()
+	m.zoomIn()
+	if m.Zoom != 4 {
+		t.Errorf("Zoom = %d, want 4", m.Zoom)
+	}
+
+	m.zoomOut()
+	if m.Zoom != 2 {
+		t.Errorf("zoomOut(): Zoom = %d, want 2", m.Zoom)
+	}
+
+	m.setZoom(0)
+	if m.Zoom != 0 || m.ViewportFrame != "" {
+		t.Error("setZoom(0) should return to fit and drop the viewport")
+	}
+}
+
+func TestZoomKeepsCentre(t *testing.T) {
+	m := newZoomModel()
+	m.setZoom(1)
+
+	// 40x10 terminal shows 20x20 source pixels at 1:1
+	src, width, height := m.viewport()
+	if src != image.Rect(90, 40, 110, 60) {
+		t.Errorf("viewport() src = %v, want centred (90,40)-(110,60)", src)
+	}
+	if width != 20 || height != 20 {
+		t.Errorf("viewport() size = %dx%d, want 20x20", width, height)
+	}
+}
+
+func TestPanClamping(t *testing.T) {
+	m := newZoomModel()
+	m.setZoom(2)
+
+	m.pan(-1000, -1000)
+	if m.PanX != 0 || m.PanY != 0 {
+		t.Errorf("pan should clamp to origin, got (%d,%d)", m.PanX, m.PanY)
+	}
+
+	m.pan(1000, 1000)
+	visW, visH := m.visibleSourceSize()
+	if m.PanX != 200-visW || m.PanY != 100-visH {
+		t.Errorf("pan should clamp to far edge, got (%d,%d)", m.PanX, m.PanY)
+	}
+}
+
+func TestDirectionKeys(t *testing.T) {
+	t.Run("arrows step frames at fit", func(t *testing.T) {
+		m := newZoomModel()
+		_, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+		if m.CurrentFrame != 1 {
+			t.Errorf("right arrow at fit: CurrentFrame = %d, want 1", m.CurrentFrame)
+		}
+	})
+
+	t.Run("arrows and hjkl pan when zoomed", func(t *testing.T) {
+		m := newZoomModel()
+		m.setZoom(1)
+		startX, startY := m.PanX, m.PanY
+
+		_, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+		if m.PanX <= startX || m.CurrentFrame != 0 {
+			t.Errorf("right arrow when zoomed should pan, PanX = %d, frame = %d", m.PanX, m.CurrentFrame)
+		}
+
+		_, _ = m.Update(tea.KeyPressMsg{Code: 'k', Text: "k"})
+		if m.PanY >= startY {
+			t.Errorf("k should pan up, PanY = %d", m.PanY)
+		}
+	})
+}
+
+func TestMouseDragPans(t *testing.T) {
+	m := newZoomModel()
+	m.setZoom(1)
+	startX := m.PanX
+
+	_, _ = m.Update(tea.MouseClickMsg{Button: tea.MouseLeft, X: 20, Y: 5})
+	_, _ = m.Update(tea.MouseMotionMsg{Button: tea.MouseLeft, X: 10, Y: 5})
+	_, _ = m.Update(tea.MouseReleaseMsg{Button: tea.MouseLeft, X: 10, Y: 5})
+
+	if m.PanX != startX+5 {
+		t.Errorf("dragging 10 cells left should pan 5 pixels right, PanX = %d, want %d", m.PanX, startX+5)
+	}
+	if !m.Paused {
+		t.Error("a drag should not toggle playback")
+	}
+}
+
+func TestCtrlWheelZooms(t *testing.T) {
+	m := newZoomModel()
+
+	_, _ = m.Update(tea.MouseWheelMsg{Button: tea.MouseWheelUp, Mod: tea.ModCtrl})
+	if m.Zoom != 1 {
+		t.Errorf("ctrl+wheel up: Zoom = %d, want 1", m.Zoom)
+	}
+	if m.CurrentFrame != 0 {
+		t.Error("ctrl+wheel should not step frames")
+	}
+}
+
+func TestViewportCellMapping(t *testing.T) {
+	m := newZoomModel()
+	m.setZoom(2)
+
+	// At 2x the 40x10 terminal shows 10x10 source pixels filling the screen
+	pt, ok := m.cellToSource(0, 0)
+	if !ok || pt != image.Pt(m.PanX, m.PanY) {
+		t.Errorf("cellToSource(0, 0) = %v, %v, want %v", pt, ok, image.Pt(m.PanX, m.PanY))
+	}
+
+	pt, ok = m.cellToSource(4, 1)
+	if !ok || pt != image.Pt(m.PanX+1, m.PanY+1) {
+		t.Errorf("cellToSource(4, 1) = %v, %v, want %v", pt, ok, image.Pt(m.PanX+1, m.PanY+1))
+	}
+
+	lines := strings.Split(strings.TrimRight(m.ViewportFrame, "\n"), "\n")
+	if len(lines) != 10 {
+		t.Errorf("viewport should render 10 rows, got %d", len(lines))
+	}
+}
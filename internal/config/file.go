@@ -0,0 +1,357 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the viewer defaults, merged from the config file and JIF_*
+// environment variables
+type Config struct {
+	// Fit is how the image is mapped to the terminal: contain, cover,
+	// stretch or original
+	Fit string `toml:"fit" yaml:"fit"`
+
+	// CellAspect is the terminal cell height/width ratio, 0 to detect it
+	CellAspect float64 `toml:"cell_aspect" yaml:"cell_aspect"`
+
+	// Speed multiplies the playback rate
+	Speed float64 `toml:"speed" yaml:"speed"`
+
+	// Loop is forever, gif to honour the file's loop count, or a number of
+	// plays
+	Loop Loop `toml:"loop" yaml:"loop"`
+
+	// Color is the colour mode: auto, truecolor, 256 or 16
+	Color string `toml:"color" yaml:"color"`
+
+	// Background is what transparent pixels are drawn against: terminal,
+	// checkerboard or a #rrggbb colour
+	Background string `toml:"background" yaml:"background"`
+
+	// Keymap is the keybinding preset: default, vim or media
+	Keymap string `toml:"keymap" yaml:"keymap"`
+
+	// Theme picks the UI colours
+	Theme Theme `toml:"theme" yaml:"theme"`
+
+	// Keys rebinds actions by name, replacing the preset's keys; an empty
+	// list disables the action
+	Keys map[string][]string `toml:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// Theme names a built-in theme and optionally overrides its colours with
+// ANSI numbers or #rrggbb hex codes
+type Theme struct {
+	// Name is auto, dark, light, high-contrast or high-contrast-light
+	Name string `toml:"name" yaml:"name"`
+
+	Accent  string `toml:"accent,omitempty" yaml:"accent,omitempty"`
+	Muted   string `toml:"muted,omitempty" yaml:"muted,omitempty"`
+	Loading string `toml:"loading,omitempty" yaml:"loading,omitempty"`
+}
+
+// Colors returns the overridden colours by name
+func (t Theme) Colors() map[string]string {
+	colors := make(map[string]string)
+	for name, value := range map[string]string{"accent": t.Accent, "muted": t.Muted, "loading": t.Loading} {
+		if value != "" {
+			colors[name] = value
+		}
+	}
+	return colors
+}
+
+// Loop is a loop setting; config files may give the number of plays as a
+// bare integer
+type Loop string
+
+// UnmarshalTOML accepts loop = 3 as well as loop = "3"
+func (l *Loop) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*l = Loop(v)
+	case int64:
+		*l = Loop(strconv.FormatInt(v, 10))
+	default:
+		return fmt.Errorf("invalid loop %v (want forever, gif or a number of plays)", v)
+	}
+	return nil
+}
+
+// fileNames are the config files looked for, in order
+var fileNames = []string{"config.toml", "config.yaml", "config.yml"}
+
+// Default returns the built-in defaults
+func Default() Config {
+	return Config{
+		Fit:        "contain",
+		Speed:      1,
+		Loop:       "forever",
+		Color:      "auto",
+		Background: "terminal",
+		Keymap:     "default",
+		Theme:      Theme{Name: "auto"},
+	}
+}
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+// Path returns the config file to read: $JIF_CONFIG if set, otherwise the
+// first of config.toml, config.yaml and config.yml that exists in
+// $XDG_CONFIG_HOME/jif, falling back to ~/.config/jif. When none exists the
+// config.toml path is returned; Load then uses the defaults, while a missing
+// $JIF_CONFIG is an error.
+func Path(getenv func(string) string) (string, error) {
+	if path := getenv("JIF_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir := getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	for _, name := range fileNames {
+		path := filepath.Join(dir, "jif", name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(dir, "jif", fileNames[0]), nil
+}
+
+// Load returns the defaults overridden by the config file and then by the
+// environment, along with the path of the file that was read, if any
+func Load(getenv func(string) string) (Config, string, error) {
+	cfg := Default()
+
+	path, err := Path(getenv)
+	if err != nil {
+		return cfg, "", err
+	}
+	if err := cfg.readFile(path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return cfg, "", err
+		}
+		// Only the default location may have no file
+		if getenv("JIF_CONFIG") != "" {
+			return cfg, "", fmt.Errorf("reading config from JIF_CONFIG: %w", err)
+		}
+		path = ""
+	}
+
+	if err := cfg.applyEnv(getenv); err != nil {
+		return cfg, path, err
+	}
+	return cfg, path, nil
+}
+
+// readFile decodes the file at path over c, as YAML when it has a .yaml or
+// .yml extension and as TOML otherwise
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(c); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	default:
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), c)
+		if undecoded := md.Undecoded(); err == nil && len(undecoded) > 0 {
+			err = fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if key, err := c.check(); err != nil {
+		return fmt.Errorf("reading config %s: %s: %w", path, key, err)
+	}
+	return nil
+}
+
+// envNames maps the settings JIF_* variables can set to the variables
+var envNames = map[string]string{
+	"fit":         "JIF_FIT",
+	"cell_aspect": "JIF_CELL_ASPECT",
+	"speed":       "JIF_SPEED",
+	"loop":        "JIF_LOOP",
+	"color":       "JIF_COLOR",
+	"background":  "JIF_BACKGROUND",
+	"keymap":      "JIF_KEYMAP",
+	"theme.name":  "JIF_THEME",
+}
+
+// applyEnv overrides c with the JIF_* environment variables that are set
+func (c *Config) applyEnv(getenv func(string) string) error {
+	texts := []struct {
+		key   string
+		value *string
+	}{
+		{"fit", &c.Fit},
+		{"loop", (*string)(&c.Loop)},
+		{"color", &c.Color},
+		{"background", &c.Background},
+		{"keymap", &c.Keymap},
+		{"theme.name", &c.Theme.Name},
+	}
+	for _, env := range texts {
+		if v := getenv(envNames[env.key]); v != "" {
+			*env.value = v
+		}
+	}
+
+	floats := []struct {
+		key   string
+		value *float64
+	}{
+		{"cell_aspect", &c.CellAspect},
+		{"speed", &c.Speed},
+	}
+	for _, env := range floats {
+		name := envNames[env.key]
+		v := getenv(name)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: want a number", name, v)
+		}
+		*env.value = f
+	}
+
+	if key, err := c.check(); err != nil {
+		return fmt.Errorf("%s: %w", cmp.Or(envNames[key], key), err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+// maxSpeed is the fastest playback speed multiplier
+const maxSpeed = 16
+
+// check validates each setting, returning the key of the first invalid one
+func (c Config) check() (string, error) {
+	checks := []struct {
+		key string
+		err error
+	}{
+		{"fit", oneOf(c.Fit, "contain", "cover", "stretch", "original")},
+		{"cell_aspect", inRange(c.CellAspect, 0, 10, "a height/width ratio, 0 to detect it")},
+		{"speed", inRange(c.Speed, 0, maxSpeed, "a multiplier")},
+		{"loop", checkLoop(string(c.Loop))},
+		{"color", oneOf(c.Color, "auto", "truecolor", "256", "16")},
+		{"background", checkBackground(c.Background)},
+		{"keymap", oneOf(c.Keymap, "default", "vim", "media")},
+		{"theme.name", oneOf(c.Theme.Name, "auto", "dark", "light", "high-contrast", "high-contrast-light")},
+		{"theme.accent", checkColor(c.Theme.Accent)},
+		{"theme.muted", checkColor(c.Theme.Muted)},
+		{"theme.loading", checkColor(c.Theme.Loading)},
+	}
+	for _, check := range checks {
+		if check.err != nil {
+			return check.key, check.err
+		}
+	}
+	return "", nil
+}
+
+// oneOf accepts an empty value or one of names, ignoring case
+func oneOf(value string, names ...string) error {
+	if value == "" || slices.Contains(names, strings.ToLower(value)) {
+		return nil
+	}
+	return fmt.Errorf("invalid value %q (want %s)", value, strings.Join(names, ", "))
+}
+
+// inRange accepts a value between lo and hi inclusive
+func inRange(value, lo, hi float64, want string) error {
+	if value < lo || value > hi {
+		return fmt.Errorf("invalid value %g (want %s between %g and %g)", value, want, lo, hi)
+	}
+	return nil
+}
+
+// checkLoop accepts forever, gif or a positive number of plays
+func checkLoop(value string) error {
+	if oneOf(value, "forever", "gif") == nil {
+		return nil
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n > 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid value %q (want forever, gif or a number of plays)", value)
+}
+
+// checkBackground accepts terminal, checkerboard or a hex colour
+func checkBackground(value string) error {
+	if oneOf(value, "terminal", "checkerboard", "checker") == nil || isHexColor(value) {
+		return nil
+	}
+	return fmt.Errorf("invalid value %q (want terminal, checkerboard or #rrggbb)", value)
+}
+
+// checkColor accepts an empty value, an ANSI colour number or a hex colour
+func checkColor(value string) error {
+	if value == "" || isHexColor(value) {
+		return nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return fmt.Errorf("invalid colour %q (want an ANSI number or #rrggbb)", value)
+}
+
+// isHexColor reports whether value is a #rgb or #rrggbb colour
+func isHexColor(value string) bool {
+	hex, ok := strings.CutPrefix(value, "#")
+	if !ok || (len(hex) != 3 && len(hex) != 6) {
+		return false
+	}
+	_, err := strconv.ParseUint(hex, 16, 32)
+	return err == nil
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+// Encode writes c as TOML
+func (c Config) Encode() (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return "", fmt.Errorf("encoding config: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    Config
+		wantErr string // Part of the expected error, empty for none
+	}{
+		{
+			name: "defaults without a file",
+			want: Default(),
+		},
+		{
+			name:    "toml file",
+			file:    "config.toml",
+			content: "fit = \"cover\"\nloop = 3\n[keys]\npause = [\"x\"]\n",
+			want: Config{Fit: "cover", Speed: 1, Loop: "3", Color: "auto", Keymap: "default",
+				Theme: Theme{Name: "auto"}, Keys: map[string][]string{"pause": {"x"}}},
+		},
+		{
+			name:    "yaml file",
+			file:    "config.yaml",
+			content: "speed: 2\nloop: 3\nkeymap: vim\ntheme:\n  name: light\n  accent: \"#ff0000\"\n",
+			want: Config{Fit: "contain", Speed: 2, Loop: "3", Color: "auto", Keymap: "vim",
+				Theme: Theme{Name: "light", Accent: "#ff0000"}},
+		},
+		{
+			name:    "environment overrides the file",
+			file:    "config.toml",
+			content: "fit = \"cover\"\nspeed = 2.0\n",
+			env:     map[string]string{"JIF_FIT": "stretch", "JIF_COLOR": "256", "JIF_THEME": "high-contrast", "JIF_BACKGROUND": "#202020"},
+			want: Config{Fit: "stretch", Speed: 2, Loop: "forever", Color: "256", Background: "#202020",
+				Keymap: "default", Theme: Theme{Name: "high-contrast"}},
+		},
+		{
+			name:    "invalid file",
+			file:    "config.toml",
+			content: "speed = \"fast\"\n",
+			wantErr: "speed",
+		},
+		{
+			name:    "invalid environment",
+			env:     map[string]string{"JIF_SPEED": "fast"},
+			wantErr: "JIF_SPEED",
+		},
+		{
+			name:    "missing JIF_CONFIG file",
+			env:     map[string]string{"JIF_CONFIG": "missing.toml"},
+			wantErr: "JIF_CONFIG",
+		},
+		{
+			name:    "empty yaml file",
+			file:    "config.yaml",
+			content: "",
+			want:    Default(),
+		},
+		{
+			name:    "unknown toml key",
+			file:    "config.toml",
+			content: "bogus = 3\n",
+			wantErr: "bogus",
+		},
+		{
+			name:    "unknown yaml key",
+			file:    "config.yaml",
+			content: "theme:\n  accnt: \"1\"\n",
+			wantErr: "accnt",
+		},
+		{
+			name:    "invalid value in the file",
+			file:    "config.toml",
+			content: "fit = \"squash\"\n",
+			wantErr: "config.toml: fit: invalid value \"squash\"",
+		},
+		{
+			name:    "invalid theme colour",
+			file:    "config.yaml",
+			content: "theme:\n  accent: red\n",
+			wantErr: "theme.accent",
+		},
+		{
+			name:    "invalid environment value",
+			env:     map[string]string{"JIF_LOOP": "0"},
+			wantErr: "JIF_LOOP: invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				if err := os.MkdirAll(filepath.Join(dir, "jif"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "jif", tt.file), []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			getenv := func(name string) string {
+				if name == "XDG_CONFIG_HOME" {
+					return dir
+				}
+				return tt.env[name]
+			}
+
+			got, path, err := Load(getenv)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if tt.file == "" && path != "" {
+				t.Errorf("Load() path = %q, want none", path)
+			}
+			if got.Fit != tt.want.Fit || got.Speed != tt.want.Speed || got.Loop != tt.want.Loop ||
+				got.Color != tt.want.Color || (tt.want.Background != "" && got.Background != tt.want.Background) || got.Keymap != tt.want.Keymap || got.Theme != tt.want.Theme ||
+				len(got.Keys) != len(tt.want.Keys) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}